@@ -1,15 +1,22 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"github.com/emirpasic/gods/maps/linkedhashmap"
 	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/go-sql-driver/mysql"
 	"github.com/kota65535/alternator/lib"
 	"github.com/kota65535/alternator/parser"
+	"io/fs"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -24,22 +31,50 @@ type DatabaseUri struct {
 	DbName   string
 	User     string
 	Password string
+	// Socket is the path of the unix domain socket to connect to instead of the host
+	Socket string
+	// Params are the query parameters passed through to the driver, such as timeout and charset
+	Params url.Values
 }
 
+const (
+	// TransportUnix is the transport of URLs like mysql+unix://user@/path/to/mysqld.sock/database
+	TransportUnix = "unix"
+	// TlsCustom is the value of tls parameter to use the CA, the certificate and the key given by tls-ca, tls-cert and tls-key parameters
+	TlsCustom = "custom"
+)
+
+// TlsFileParams are the query parameters of the file paths used for tls=custom, which are not passed to the driver
+var TlsFileParams = []string{"tls-ca", "tls-cert", "tls-key"}
+
 func NewDatabaseUri(uri string) (*DatabaseUri, error) {
 	p, err := url.Parse(uri)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URI: %s : %w", uri, err)
 	}
 
-	dialect := p.Scheme
+	dialect, transport, _ := strings.Cut(p.Scheme, "+")
 	if !SupportedDialects.Contains(dialect) {
-		return nil, fmt.Errorf("unsupported dialect: %s : %w", uri, err)
+		return nil, fmt.Errorf("unsupported dialect: %s", uri)
+	}
+	if transport != "" && transport != TransportUnix {
+		return nil, fmt.Errorf("unsupported transport: %s", uri)
 	}
 
 	host := p.Host
+	socket := ""
 	dbName := ""
-	if len(p.Path) > 0 {
+	if transport == TransportUnix {
+		// The socket path ends with ".sock", followed by the optional database name
+		socket = p.Path
+		if i := strings.Index(p.Path, ".sock/"); i >= 0 {
+			socket = p.Path[:i+len(".sock")]
+			dbName = p.Path[i+len(".sock/"):]
+		}
+		if socket == "" {
+			return nil, fmt.Errorf("socket path is required: %s", uri)
+		}
+	} else if len(p.Path) > 0 {
 		dbName = p.Path[1:]
 	}
 	if dbName == "" && !managesAllDatabases {
@@ -49,27 +84,125 @@ func NewDatabaseUri(uri string) (*DatabaseUri, error) {
 	user := p.User.Username()
 	password, _ := p.User.Password()
 
-	return &DatabaseUri{
-		dialect,
-		host,
-		dbName,
-		user,
-		password,
-	}, nil
+	params := p.Query()
+	if err := registerTlsConfig(params, p.Hostname()); err != nil {
+		return nil, err
+	}
+
+	ret := &DatabaseUri{
+		Dialect:  dialect,
+		Host:     host,
+		DbName:   dbName,
+		User:     user,
+		Password: password,
+		Socket:   socket,
+		Params:   params,
+	}
+	if err := fillCredentials(ret); err != nil {
+		return nil, err
+	}
+	// Validate the parameters by the driver
+	if _, err := mysql.ParseDSN(ret.Dsn()); err != nil {
+		return nil, fmt.Errorf("invalid database URI: %s : %w", uri, err)
+	}
+	return ret, nil
+}
+
+// registerTlsConfig registers the TLS config to the driver if tls=custom, and replaces the parameter with its name.
+// The TLS config is named after the files and the server name, so that databases with different configs can be used at once.
+func registerTlsConfig(params url.Values, serverName string) error {
+	files := map[string]string{}
+	for _, k := range TlsFileParams {
+		if v := params.Get(k); v != "" {
+			files[k] = v
+		}
+		params.Del(k)
+	}
+	if len(files) > 0 && params.Get("tls") == "" {
+		params.Set("tls", TlsCustom)
+	}
+	if params.Get("tls") != TlsCustom {
+		if len(files) > 0 {
+			return fmt.Errorf("%s parameters require tls=%s", strings.Join(TlsFileParams, ", "), TlsCustom)
+		}
+		return nil
+	}
+
+	config := &tls.Config{ServerName: serverName}
+	if ca, ok := files["tls-ca"]; ok {
+		b, err := os.ReadFile(ca)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %s : %w", ca, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return fmt.Errorf("failed to parse CA file: %s", ca)
+		}
+		config.RootCAs = pool
+	}
+	cert, hasCert := files["tls-cert"]
+	key, hasKey := files["tls-key"]
+	if hasCert != hasKey {
+		return fmt.Errorf("both tls-cert and tls-key are required for client certificate")
+	}
+	if hasCert {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %s, %s : %w", cert, key, err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{files["tls-ca"], cert, key, serverName}, "\n")))
+	name := "alternator-" + hex.EncodeToString(sum[:8])
+	if err := mysql.RegisterTLSConfig(name, config); err != nil {
+		return fmt.Errorf("failed to register TLS config : %w", err)
+	}
+	params.Set("tls", name)
+	return nil
+}
+
+// Addr returns the host or the socket path to connect to
+func (r DatabaseUri) Addr() string {
+	if r.Socket != "" {
+		return r.Socket
+	}
+	return r.Host
 }
 
 func (r DatabaseUri) Dsn() string {
-	return fmt.Sprintf("%s%s@(%s)/%s", r.User, optS(r.Password, ":%s"), r.Host, r.DbName)
+	return r.dsn(r.DbName)
 }
 
 func (r DatabaseUri) DsnWoDbName() string {
-	return fmt.Sprintf("%s%s@(%s)/", r.User, optS(r.Password, ":%s"), r.Host)
+	return r.dsn("")
+}
+
+func (r DatabaseUri) dsn(dbName string) string {
+	network := ""
+	if r.Socket != "" {
+		network = TransportUnix
+	}
+	dsn := fmt.Sprintf("%s%s@%s(%s)/%s", r.User, optS(r.Password, ":%s"), network, r.Addr(), dbName)
+	if len(r.Params) > 0 {
+		dsn += "?" + r.Params.Encode()
+	}
+	return dsn
 }
 
 type Alternator struct {
-	DbUri        *DatabaseUri
-	Db           *sql.DB
-	GlobalConfig *parser.GlobalConfig
+	DbUri         *DatabaseUri
+	Db            *sql.DB
+	GlobalConfig  *parser.GlobalConfig
+	ServerVersion lib.ServerVersion
+	// Filter selects databases, tables and columns to manage
+	Filter *lib.Filter
+	// Concurrency is the maximum number of queries fetching remote schemas at the same time
+	Concurrency int
+	// FetchMethod is how remote table definitions are fetched
+	FetchMethod string
+	// RenameThreshold is the minimum similarity score of dropped and added tables or columns to be renamed
+	RenameThreshold float64
 }
 
 func NewAlternator(dbUri *DatabaseUri) (*Alternator, error) {
@@ -80,37 +213,65 @@ func NewAlternator(dbUri *DatabaseUri) (*Alternator, error) {
 	}
 
 	globalConfig, err := fetchGlobalConfig(db)
+	if err != nil && canPromptPassword(dbUri, err) {
+		// Retry with the password typed by the user
+		_ = db.Close()
+		dbUri.Password, err = promptPassword(dbUri)
+		if err != nil {
+			return nil, err
+		}
+		db, err = sql.Open(dbUri.Dialect, dbUri.DsnWoDbName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database connection. DSN = %s : %w", dbUri.DsnWoDbName(), err)
+		}
+		globalConfig, err = fetchGlobalConfig(db)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch global config : %w", err)
 	}
 
+	// Keep connections of concurrent queries for reuse
+	db.SetMaxIdleConns(concurrency)
+
+	serverVersion, err := fetchServerVersion(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch server version : %w", err)
+	}
+
 	return &Alternator{
-		dbUri,
-		db,
-		globalConfig,
+		DbUri:           dbUri,
+		Db:              db,
+		GlobalConfig:    globalConfig,
+		ServerVersion:   serverVersion,
+		Filter:          &filter,
+		Concurrency:     concurrency,
+		FetchMethod:     fetchMethod,
+		RenameThreshold: renameThreshold,
 	}, nil
 }
 
 func (r *Alternator) ReadSchemas(schema string) ([]*lib.Schema, error) {
-	var allowedDbNames *hashset.Set
-	if r.DbUri.DbName != "" {
-		allowedDbNames = hashset.New(r.DbUri.DbName)
-	} else {
-		allowedDbNames = hashset.New()
-	}
-	schemas, err := lib.NewSchemas(schema, r.GlobalConfig, allowedDbNames)
+	schemas, err := lib.NewSchemas(schema, r.GlobalConfig, r.allowedDbNames())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create shema : %w", err)
 	}
-	return schemas, nil
+	return r.Filter.Apply(schemas), nil
 }
 
 func (r *Alternator) ReadSchemasFromFile(path string) ([]*lib.Schema, error) {
-	b, err := os.ReadFile(path)
+	sources, err := readSchemaFiles(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read shema file: %s : %w", path, err)
+		return nil, err
 	}
-	return r.ReadSchemas(string(b))
+	return r.ReadSchemasFromSources(sources)
+}
+
+func (r *Alternator) ReadSchemasFromSources(sources []lib.SchemaSource) ([]*lib.Schema, error) {
+	schemas, err := lib.NewSchemasFromSources(sources, r.GlobalConfig, r.allowedDbNames())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shema : %w", err)
+	}
+	return r.Filter.Apply(schemas), nil
 }
 
 func (r *Alternator) FetchSchemas() ([]*lib.Schema, error) {
@@ -138,17 +299,37 @@ func (r *Alternator) GetAlterations(schema string) (*lib.DatabaseAlterations, []
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to read local shema : %w", err)
 	}
+	return r.getAlterations(localSchemas)
+}
+
+func (r *Alternator) GetAlterationsFromFile(path string) (*lib.DatabaseAlterations, []*lib.Schema, []*lib.Schema, error) {
+	sources, err := readSchemaFiles(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read local shema : %w", err)
+	}
+	return r.GetAlterationsFromSources(sources)
+}
+
+func (r *Alternator) GetAlterationsFromSources(sources []lib.SchemaSource) (*lib.DatabaseAlterations, []*lib.Schema, []*lib.Schema, error) {
+	localSchemas, err := r.ReadSchemasFromSources(sources)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read local shema : %w", err)
+	}
+	return r.getAlterations(localSchemas)
+}
+
+func (r *Alternator) getAlterations(localSchemas []*lib.Schema) (*lib.DatabaseAlterations, []*lib.Schema, []*lib.Schema, error) {
 	remoteSchemas, err := r.FetchSchemas()
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to fetch remote schema : %w", err)
 	}
-	remoteSchemas = r.sortRemoteSchema(remoteSchemas, localSchemas)
+	remoteSchemas = sortRemoteSchema(remoteSchemas, localSchemas)
 
-	return lib.NewDatabaseAlterations(remoteSchemas, localSchemas), remoteSchemas, localSchemas, nil
+	return lib.NewDatabaseAlterationsWithRenameThreshold(remoteSchemas, localSchemas, r.RenameThreshold), remoteSchemas, localSchemas, nil
 }
 
 // sort remote schemas by order of local schemas
-func (r *Alternator) sortRemoteSchema(remoteSchema []*lib.Schema, localSchema []*lib.Schema) []*lib.Schema {
+func sortRemoteSchema(remoteSchema []*lib.Schema, localSchema []*lib.Schema) []*lib.Schema {
 	ret := []*lib.Schema{}
 	dbMap := linkedhashmap.New()
 	for _, s := range remoteSchema {
@@ -178,7 +359,7 @@ func (r *Alternator) sortRemoteSchema(remoteSchema []*lib.Schema, localSchema []
 					tables = append(tables, v.(*parser.CreateTableStatement))
 				}
 			}
-			ret = append(ret, &lib.Schema{remoteSchema[i].Database, tables})
+			ret = append(ret, &lib.Schema{Database: remoteSchema[i].Database, Tables: tables})
 			dbMap.Remove(remoteSchema[i].Database.DbName)
 		}
 	}
@@ -190,12 +371,11 @@ func (r *Alternator) sortRemoteSchema(remoteSchema []*lib.Schema, localSchema []
 	return ret
 }
 
-func (r *Alternator) GetAlterationsFromFile(path string) (*lib.DatabaseAlterations, []*lib.Schema, []*lib.Schema, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to read shema file: %s : %w", path, err)
+func (r *Alternator) allowedDbNames() *hashset.Set {
+	if r.DbUri.DbName != "" {
+		return hashset.New(r.DbUri.DbName)
 	}
-	return r.GetAlterations(string(b))
+	return hashset.New()
 }
 
 func (r *Alternator) Close() error {
@@ -228,7 +408,9 @@ func (r *Alternator) fetchFromDatabases() ([]*lib.Schema, error) {
 }
 
 func (r *Alternator) fetchFromDatabase(dbName string) (*lib.Schema, error) {
-	var strs []string
+	if !r.Filter.Database(dbName) {
+		return nil, nil
+	}
 
 	databaseSchema, err := r.getCreateDatabase(dbName)
 	if err != nil {
@@ -239,28 +421,97 @@ func (r *Alternator) fetchFromDatabase(dbName string) (*lib.Schema, error) {
 		return nil, nil
 	}
 
-	strs = append(strs, databaseSchema)
-	strs = append(strs, fmt.Sprintf("USE `%s`", dbName))
-
 	tables, err := r.listTables(dbName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch remote table names : %w", err)
 	}
 
-	for _, t := range tables {
-		tableSchema, err := r.getCreateTable(dbName, t)
+	return r.fetchTables(dbName, databaseSchema, tables)
+}
+
+// FetchTable returns the schemas in which only the table is fetched again from the remote database.
+// It is used to verify ALTER TABLE statements, which change nothing but the table, without fetching all the schemas.
+func (r *Alternator) FetchTable(schemas []*lib.Schema, dbName string, tableName string) ([]*lib.Schema, error) {
+	if !r.Filter.Database(dbName) || !r.Filter.Table(dbName, tableName) || r.isHistoryTable(dbName, tableName) {
+		return schemas, nil
+	}
+	databaseSchema, err := r.getCreateDatabase(dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote database creation statement : %w", err)
+	}
+	if databaseSchema == "" {
+		return nil, fmt.Errorf("database `%s` is not found", dbName)
+	}
+	schema, err := r.fetchTables(dbName, databaseSchema, []string{tableName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote table schema : %w", err)
+	}
+
+	var ret []*lib.Schema
+	found := false
+	for _, s := range schemas {
+		if s.Database.DbName != dbName {
+			ret = append(ret, s)
+			continue
+		}
+		found = true
+		tables := []*parser.CreateTableStatement{}
+		replaced := false
+		for _, t := range s.Tables {
+			if t.TableName == tableName {
+				tables = append(tables, schema.Tables...)
+				replaced = true
+			} else {
+				tables = append(tables, t)
+			}
+		}
+		if !replaced {
+			tables = append(tables, schema.Tables...)
+		}
+		ret = append(ret, &lib.Schema{Database: s.Database, Tables: tables})
+	}
+	if !found {
+		ret = append(ret, schema)
+	}
+	return ret, nil
+}
+
+// fetchTables returns the schema of the database consisting of the tables
+func (r *Alternator) fetchTables(dbName string, databaseSchema string, tables []string) (*lib.Schema, error) {
+	strs := []string{databaseSchema, fmt.Sprintf("USE `%s`", dbName)}
+
+	if r.FetchMethod == FetchMethodInformationSchema {
+		statements, err := parser.NewParser(strings.NewReader(strings.Join(strs, ";\n"))).Parse()
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch remote table creation statement : %w", err)
+			return nil, fmt.Errorf("failed to parse schema : %w", err)
 		}
-		strs = append(strs, tableSchema)
+		tableStatements, err := r.buildCreateTables(dbName, tables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch remote table definitions : %w", err)
+		}
+		schemas, err := lib.NewSchemasFromStatements(append(statements, tableStatements...), r.GlobalConfig, hashset.New(dbName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shema : %w", err)
+		}
+		return r.Filter.Apply(schemas)[0], nil
 	}
 
+	// Tables are fetched concurrently, since each of them requires a round trip
+	tableSchemas, err := parallelMap(r.Concurrency, tables, func(t string) (string, error) {
+		return r.getCreateTable(dbName, t)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote table creation statement : %w", err)
+	}
+	strs = append(strs, tableSchemas...)
+
 	schemas, err := lib.NewSchemas(strings.Join(strs, ";\n"), r.GlobalConfig, hashset.New(dbName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create shema : %w", err)
 	}
 
-	return schemas[0], nil
+	// Tables are already filtered, but columns are not
+	return r.Filter.Apply(schemas)[0], nil
 }
 
 func (r *Alternator) getCreateDatabase(name string) (string, error) {
@@ -291,6 +542,9 @@ func (r *Alternator) getCreateTable(dbName string, tableName string) (string, er
 	for rows.Next() {
 		_ = rows.Scan(&tableName, &statement)
 	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to query \"SHOW CREATE TABLE\" : %w", err)
+	}
 	return statement, nil
 }
 
@@ -316,7 +570,7 @@ func (r *Alternator) listUserDefinedDatabases() ([]string, error) {
 	}
 	filtered := []string{}
 	for _, d := range databases {
-		if !IgnoredDatabases.Contains(d) {
+		if !IgnoredDatabases.Contains(d) && r.Filter.Database(d) {
 			filtered = append(filtered, d)
 		}
 	}
@@ -334,11 +588,42 @@ func (r *Alternator) listTables(dbName string) ([]string, error) {
 	var table string
 	for rows.Next() {
 		_ = rows.Scan(&table)
-		tables = append(tables, table)
+		if r.Filter.Table(dbName, table) && !r.isHistoryTable(dbName, table) {
+			tables = append(tables, table)
+		}
 	}
 	return tables, nil
 }
 
+// getTableSize returns the approximate number of rows and the data and index size in bytes of the table
+func (r *Alternator) getTableSize(dbName string, tableName string) (int64, int64, error) {
+	rows, err := r.Db.Query("SELECT IFNULL(TABLE_ROWS, 0), IFNULL(DATA_LENGTH, 0) + IFNULL(INDEX_LENGTH, 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?", dbName, tableName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query table size of `%s`.`%s` : %w", dbName, tableName, err)
+	}
+	defer rows.Close()
+	var tableRows int64
+	var bytes int64
+	for rows.Next() {
+		if err := rows.Scan(&tableRows, &bytes); err != nil {
+			return 0, 0, fmt.Errorf("failed to read table size of `%s`.`%s` : %w", dbName, tableName, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read table size of `%s`.`%s` : %w", dbName, tableName, err)
+	}
+	return tableRows, bytes, nil
+}
+
+func fetchServerVersion(db *sql.DB) (lib.ServerVersion, error) {
+	var version string
+	err := db.QueryRow("SELECT VERSION()").Scan(&version)
+	if err != nil {
+		return lib.ServerVersion{}, fmt.Errorf("failed to query \"SELECT VERSION()\" : %w", err)
+	}
+	return lib.ParseServerVersion(version)
+}
+
 func fetchGlobalConfig(db *sql.DB) (*parser.GlobalConfig, error) {
 	rows1, err := db.Query("SHOW GLOBAL VARIABLES")
 	if err != nil {
@@ -386,3 +671,58 @@ func fetchGlobalConfig(db *sql.DB) (*parser.GlobalConfig, error) {
 		Encryption:           variables["default_table_encryption"],
 	}, nil
 }
+
+// listSchemaFiles returns schema file paths specified by a file path, a directory or a glob pattern.
+// Directories are searched recursively for *.sql files, and the result is sorted to be deterministic.
+func listSchemaFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err == nil {
+		if !info.IsDir() {
+			return []string{path}, nil
+		}
+		var files []string
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".sql") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search schema files: %s : %w", path, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no schema file found in directory: %s", path)
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	files, gErr := filepath.Glob(path)
+	if gErr != nil {
+		return nil, fmt.Errorf("invalid glob pattern: %s : %w", path, gErr)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("failed to read shema file: %s : %w", path, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func readSchemaFiles(path string) ([]lib.SchemaSource, error) {
+	files, err := listSchemaFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var sources []lib.SchemaSource
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shema file: %s : %w", f, err)
+		}
+		sources = append(sources, lib.SchemaSource{Name: f, Body: string(b)})
+	}
+	return sources, nil
+}
@@ -3,37 +3,99 @@ package cmd
 import (
 	_ "embed"
 	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/kota65535/alternator/lib"
 	"github.com/spf13/cobra"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 //go:embed apply.tmpl
 var applyUsage string
 
+// DefaultJournalDir is the directory to save journals of apply command
+var DefaultJournalDir = filepath.Join(".alternator", "journal")
+
 type ApplyParams struct {
-	AutoApprove bool
+	AutoApprove     bool
+	Format          string
+	Resume          bool
+	JournalDir      string
+	Osc             OnlineSchemaChange
+	OscPrint        bool
+	AlgorithmPolicy string
+	SplitStatements bool
+	// Advisory lock taken during apply, so that concurrent applies to the same database do not interleave
+	LockName    string
+	LockTimeout time.Duration
+	// Kinds of destructive statements allowed to execute
+	AllowDropDatabase bool
+	AllowDropTable    bool
+	AllowDropColumn   bool
 }
 
+const (
+	// AlgorithmPolicyNone executes statements as they are, which lets MySQL choose algorithms
+	AlgorithmPolicyNone = "none"
+	// AlgorithmPolicyEnforce appends ALGORITHM and LOCK clauses, so that MySQL fails rather than falls back to slower algorithms
+	AlgorithmPolicyEnforce = "enforce"
+	// AlgorithmPolicyNoCopy refuses statements requiring COPY algorithm in addition to AlgorithmPolicyEnforce
+	AlgorithmPolicyNoCopy = "no-copy"
+)
+
+var SupportedAlgorithmPolicies = hashset.New(AlgorithmPolicyNone, AlgorithmPolicyEnforce, AlgorithmPolicyNoCopy)
+
 func init() {
 	var params ApplyParams
 
 	c := &cobra.Command{
-		Use:   "apply <schema-file> <database-url>",
+		Use:   "apply <schema-file|plan-file> <database-url>",
 		Short: "Update the remote database schema according to the local schema file.",
 		Long:  "Update the remote database schema according to the local schema file.",
-		Args:  cobra.ExactArgs(2),
+		Args:  envArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
+			args, err := argsWithEnv(args, ArgSchema, ArgUrl)
+			cobra.CheckErr(err)
+			checkFormat(params.Format)
+			if !SupportedAlgorithmPolicies.Contains(params.AlgorithmPolicy) {
+				cobra.CheckErr(fmt.Errorf("unsupported algorithm policy: %s", params.AlgorithmPolicy))
+			}
+			if params.Osc.Tool != "" && !SupportedOscTools.Contains(params.Osc.Tool) {
+				cobra.CheckErr(fmt.Errorf("unsupported online schema change tool: %s", params.Osc.Tool))
+			}
+			cobra.CheckErr(checkPasswordStdin(params))
 			ApplyCmd(args[0], args[1], params)
 		},
 	}
 	c.Flags().BoolVar(&params.AutoApprove, "auto-approve", false, "Approve automatically")
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text or json)")
+	c.Flags().BoolVar(&params.Resume, "resume", false, "Resume the previous apply failed in the middle")
+	c.Flags().StringVar(&params.JournalDir, "journal-dir", DefaultJournalDir, "Directory to save journals of executed statements")
+	c.Flags().StringVar(&params.Osc.Tool, "osc", "", "Online schema change tool to alter large tables (gh-ost or pt-osc)")
+	c.Flags().StringVar(&params.Osc.Path, "osc-path", "", "Path of the online schema change tool")
+	c.Flags().Int64Var(&params.Osc.MinRows, "osc-min-rows", 1000000, "Tables with at least this number of rows are altered by the online schema change tool")
+	c.Flags().Int64Var(&params.Osc.MinBytes, "osc-min-bytes", 0, "Tables with at least this data and index size are altered by the online schema change tool")
+	c.Flags().StringSliceVar(&params.Osc.Args, "osc-args", nil, "Additional arguments passed to the online schema change tool")
+	c.Flags().BoolVar(&params.SplitStatements, "split-statements", false, "Execute each clause by its own ALTER TABLE statement instead of merging clauses altering the same table")
+	c.Flags().BoolVar(&params.AllowDropDatabase, "allow-drop-database", false, "Allow dropping databases")
+	c.Flags().BoolVar(&params.AllowDropTable, "allow-drop-table", false, "Allow dropping tables")
+	c.Flags().BoolVar(&params.AllowDropColumn, "allow-drop-column", false, "Allow dropping columns")
+	c.Flags().StringVar(&params.AlgorithmPolicy, "algorithm-policy", AlgorithmPolicyNone, "Policy of online DDL algorithms (none, enforce or no-copy)")
+	c.Flags().BoolVar(&params.OscPrint, "osc-print", false, "Print the statements and the online schema change commands without executing them")
+	c.Flags().StringVar(&params.LockName, "lock-name", "", "Name of the advisory lock taken during apply (default \"alternator:<database>\")")
+	c.Flags().DurationVar(&params.LockTimeout, "lock-timeout", 0, "Time to wait for the lock held by another apply (0 fails immediately, negative waits indefinitely)")
 	rootCmd.AddCommand(c)
 	c.SetUsageTemplate(applyUsage)
 }
 
 func ApplyCmd(path string, uri string, params ApplyParams) *lib.DatabaseAlterations {
+	if params.JournalDir == "" {
+		params.JournalDir = DefaultJournalDir
+	}
+
 	dbUri, err := NewDatabaseUri(uri)
 	cobra.CheckErr(err)
 
@@ -41,30 +103,184 @@ func ApplyCmd(path string, uri string, params ApplyParams) *lib.DatabaseAlterati
 	cobra.CheckErr(err)
 	defer alternator.Close()
 
-	alt, _, _, err := alternator.GetAlterationsFromFile(path)
+	// Hold the lock from computing the diff until the end of execution
+	lockName := params.LockName
+	if lockName == "" {
+		lockName = defaultLockName(dbUri)
+	}
+	lock, err := alternator.Lock(lockName, params.LockTimeout)
 	cobra.CheckErr(err)
+	defer lock.Release()
 
-	// Show diff
-	ePrintln(strings.Repeat("―", width))
-	bPrintln("Schema diff:")
-	bPrintln()
-	for _, s := range alt.Diff() {
-		printlnDiff(s)
+	plan, err := readPlanFile(path)
+	cobra.CheckErr(err)
+	if plan != nil {
+		if params.Resume {
+			resumeJournal(alternator, planFingerprint(plan.Fingerprint, plan.Statements), params)
+			return nil
+		}
+		applyPlanFile(alternator, plan, params)
+		return nil
+	}
+
+	if params.Resume {
+		localSchemas, err := alternator.ReadSchemasFromFile(path)
+		cobra.CheckErr(err)
+		resumeJournal(alternator, lib.Fingerprint(localSchemas), params)
+		return nil
+	}
+
+	alt, remoteSchemas, localSchemas, err := alternator.GetAlterationsFromFile(path)
+	cobra.CheckErr(err)
+	alt.SetSplitStatements(params.SplitStatements)
+
+	statements := alt.Statements()
+	if params.Format == FormatJson {
+		report := lib.NewReport(alt)
+		report.SetAlgorithms(lib.OnlineDdls(alt, alternator.ServerVersion))
+		printJson(report)
+	} else {
+		// Show diff
+		ePrintln(strings.Repeat("―", width))
+		bPrintln("Schema diff:")
+		bPrintln()
+		for _, s := range alt.Diff() {
+			printlnDiff(s)
+		}
 	}
 
 	// Show statements to execute
 	ePrintln(strings.Repeat("―", width))
-	statements := alt.Statements()
 	if len(statements) == 0 {
 		bPrintln("Your database schema is up-to-date! No change required.")
 		return nil
 	}
-	bPrintln("Statements to execute:")
+	journal := NewJournal(lib.Fingerprint(localSchemas), lib.Fingerprint(remoteSchemas), statements)
+	journal.OnlineDdls = lib.OnlineDdls(alt, alternator.ServerVersion)
+	journal.Destructions = lib.Destructions(alt)
+	executeStatements(alternator, journal, remoteSchemas, params)
+
+	return alt
+}
+
+// applyPlanFile executes statements of the saved plan, only if the remote schema has not changed since the plan was created.
+func applyPlanFile(alternator *Alternator, plan *PlanFile, params ApplyParams) {
+	remoteSchemas, err := alternator.FetchSchemas()
+	cobra.CheckErr(err)
+	if lib.Fingerprint(remoteSchemas) != plan.Fingerprint {
+		cobra.CheckErr(fmt.Errorf("the remote database schema has changed since the plan was created. Run plan again"))
+	}
+	alt, err := planAlterations(alternator, plan, remoteSchemas)
+	cobra.CheckErr(err)
+
+	if params.Format == FormatJson {
+		report := lib.NewReport(alt)
+		report.SetAlgorithms(lib.OnlineDdls(alt, alternator.ServerVersion))
+		printJson(report)
+	}
+
+	ePrintln(strings.Repeat("―", width))
+	if len(plan.Statements) == 0 {
+		bPrintln("Your database schema is up-to-date! No change required.")
+		return
+	}
+	journal := NewJournal(planFingerprint(plan.Fingerprint, plan.Statements), plan.Fingerprint, plan.Statements)
+	journal.OnlineDdls = lib.OnlineDdls(alt, alternator.ServerVersion)
+	journal.Destructions = lib.Destructions(alt)
+	executeStatements(alternator, journal, remoteSchemas, params)
+}
+
+// planAlterations computes the alterations of the plan again from its schema files and the remote schemas, which have
+// been verified by the fingerprint. It returns an error if their statements differ from those of the plan, such as when
+// the plan file has been edited.
+func planAlterations(alternator *Alternator, plan *PlanFile, remoteSchemas []*lib.Schema) (*lib.DatabaseAlterations, error) {
+	localSchemas, err := alternator.ReadSchemasFromSources(plan.Schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema files of the plan : %w", err)
+	}
+	remoteSchemas = sortRemoteSchema(remoteSchemas, localSchemas)
+	alt := lib.NewDatabaseAlterationsWithRenameThreshold(remoteSchemas, localSchemas, plan.RenameThreshold)
+	alt.SetSplitStatements(plan.SplitStatements)
+	if !slices.Equal(alt.Statements(), plan.Statements) {
+		return nil, fmt.Errorf("statements of the plan do not match its schema files. " +
+			"The plan file may have been edited, or created with different filters. Run plan again")
+	}
+	return alt, nil
+}
+
+// resumeJournal executes the rest of statements recorded in the journal of the source
+func resumeJournal(alternator *Alternator, source string, params ApplyParams) {
+	journal, err := findJournal(params.JournalDir, source)
+	cobra.CheckErr(err)
+	if journal == nil {
+		cobra.CheckErr(fmt.Errorf("no journal to resume is found in %s", params.JournalDir))
+	}
+
+	remoteSchemas, err := alternator.FetchSchemas()
+	cobra.CheckErr(err)
+	i, err := journal.ResumeIndex(lib.Fingerprint(remoteSchemas))
+	cobra.CheckErr(err)
+	journal.Completed = journal.Completed[:i]
+
+	ePrintln(strings.Repeat("―", width))
+	bPrintf("Resuming the previous apply. %d of %d statements have been completed:\n", i, len(journal.Statements))
 	bPrintln()
-	for _, s := range alt.Statements() {
-		fmt.Println(s)
+	for _, e := range journal.Completed {
+		Green.Fprintf(os.Stderr, "  ✓ %s\n", firstLine(e.Statement))
 	}
 	bPrintln()
+	if i == len(journal.Statements) {
+		cobra.CheckErr(removeJournal(params.JournalDir, journal))
+		bPrintln("All statements have been completed. No change required.")
+		return
+	}
+	executeStatements(alternator, journal, remoteSchemas, params)
+}
+
+// executeStatements executes statements of the journal which have not been completed yet.
+// Each executed statement is recorded in the journal, so that apply can be resumed after a failure.
+func executeStatements(alternator *Alternator, journal *Journal, remoteSchemas []*lib.Schema, params ApplyParams) {
+	statements := journal.Statements[len(journal.Completed):]
+
+	// Statements are already shown in JSON format
+	if params.Format != FormatJson {
+		bPrintln("Statements to execute:")
+		bPrintln()
+		for _, s := range statements {
+			fmt.Println(s)
+		}
+		printOnlineDdls(statements, journal.OnlineDdls, alternator.ServerVersion)
+		printDestructive(statements, journal.Destructions)
+		bPrintln()
+	}
+
+	cobra.CheckErr(checkDestructions(statements, journal.Destructions, params))
+
+	if params.AlgorithmPolicy == AlgorithmPolicyNoCopy {
+		var copying []string
+		for _, s := range statements {
+			if ddl, ok := journal.OnlineDdls[s]; ok && ddl.Algorithm == lib.AlgorithmCopy {
+				copying = append(copying, firstLine(s))
+			}
+		}
+		if len(copying) > 0 {
+			cobra.CheckErr(fmt.Errorf("refused to apply statements requiring COPY algorithm:\n  %s", strings.Join(copying, "\n  ")))
+		}
+	}
+
+	// Resolve statements executed by online schema change tools
+	commands := make([][]string, len(statements))
+	if params.Osc.Tool != "" {
+		for i, s := range statements {
+			command, err := params.Osc.Command(alternator, s)
+			cobra.CheckErr(err)
+			commands[i] = command
+		}
+	}
+	if params.OscPrint {
+		printExecution(alternator, statements, commands)
+		return
+	}
 
 	// Apply
 	ePrintln(strings.Repeat("―", width))
@@ -75,12 +291,150 @@ func ApplyCmd(path string, uri string, params ApplyParams) *lib.DatabaseAlterati
 		ePrintln()
 	}
 
-	for _, s := range alt.Statements() {
-		ePrintf("Executing: %s\n", s)
-		_, err := alternator.Db.Exec(s)
-		cobra.CheckErr(err)
+	history := NewHistory(journal)
+	for i, s := range statements {
+		n := len(journal.Completed)
+		// Record the statement before executing it, so that apply can be resumed even if it stops before completing
+		journal.Executing = s
+		cobra.CheckErr(writeJournal(params.JournalDir, journal))
+		var err error
+		if commands[i] != nil {
+			ePrintf("Executing: %s\n", commandString(commands[i]))
+			err = params.Osc.run(commands[i], alternator.DbUri.Password)
+		} else {
+			s := withAlgorithm(s, journal.OnlineDdls, params.AlgorithmPolicy)
+			ePrintf("Executing: %s\n", s)
+			_, err = alternator.Db.Exec(s)
+		}
+		if err != nil {
+			history.Fail(n, err)
+			recordHistory(alternator, history)
+			printPartialCompletion(journal, params)
+			cobra.CheckErr(fmt.Errorf("failed to execute statement : %w", err))
+		}
+		remoteSchemas, err = fetchExecuted(alternator, remoteSchemas, s)
+		if err != nil {
+			history.Fail(n, fmt.Errorf("failed to verify the executed statement : %w", err))
+			recordHistory(alternator, history)
+			printPartialCompletion(journal, params)
+			cobra.CheckErr(fmt.Errorf("failed to verify the executed statement : %w", err))
+		}
+		history.Succeed(n)
+		journal.Completed = append(journal.Completed, &JournalEntry{
+			Statement:         s,
+			RemoteFingerprint: lib.Fingerprint(remoteSchemas),
+		})
+		journal.Executing = ""
+		cobra.CheckErr(writeJournal(params.JournalDir, journal))
 	}
+	recordHistory(alternator, history)
+	cobra.CheckErr(removeJournal(params.JournalDir, journal))
 	bPrintln("\nFinished!")
+}
 
-	return alt
+// fetchExecuted returns the remote schemas after executing the statement.
+// Only the table is fetched for ALTER TABLE statements, so that large schemas are not fetched for every statement.
+func fetchExecuted(alternator *Alternator, remoteSchemas []*lib.Schema, statement string) ([]*lib.Schema, error) {
+	if dbName, tableName, _, ok := parseAlterTable(statement); ok {
+		return alternator.FetchTable(remoteSchemas, dbName, tableName)
+	}
+	return alternator.FetchSchemas()
+}
+
+// checkPasswordStdin returns an error if the password is read from stdin but the confirmation also needs it.
+// Stdin is read up to EOF for the password, so nothing is left for the answer.
+func checkPasswordStdin(params ApplyParams) error {
+	if passwordStdin && !params.AutoApprove && !params.OscPrint {
+		return fmt.Errorf("--password-stdin requires --auto-approve, since stdin cannot be used for both the password and the confirmation")
+	}
+	return nil
+}
+
+// checkDestructions returns an error if any of the statements is destructive and not allowed by the flags
+func checkDestructions(statements []string, destructions map[string]lib.Destruction, params ApplyParams) error {
+	allowed := map[lib.Destruction]bool{
+		lib.DestructionDropDatabase: params.AllowDropDatabase,
+		lib.DestructionDropTable:    params.AllowDropTable,
+		lib.DestructionDropColumn:   params.AllowDropColumn,
+	}
+	var refused []string
+	var flags []string
+	for _, s := range statements {
+		d, ok := destructions[s]
+		if !ok || allowed[d] {
+			continue
+		}
+		refused = append(refused, firstLine(s))
+		if flag := "--allow-" + string(d); !lib.Contains(flags, flag) {
+			flags = append(flags, flag)
+		}
+	}
+	if len(refused) > 0 {
+		return fmt.Errorf("refused to apply destructive statements. Use %s to allow them:\n  %s",
+			strings.Join(flags, ", "), strings.Join(refused, "\n  "))
+	}
+	return nil
+}
+
+// withAlgorithm appends ALGORITHM and LOCK clauses to the statement according to the policy
+func withAlgorithm(statement string, ddls map[string]lib.OnlineDdl, policy string) string {
+	if policy != AlgorithmPolicyEnforce && policy != AlgorithmPolicyNoCopy {
+		return statement
+	}
+	ddl, ok := ddls[statement]
+	if !ok {
+		return statement
+	}
+	return fmt.Sprintf("%s, %s;", strings.TrimSuffix(statement, ";"), ddl.Clause())
+}
+
+// printExecution shows how statements are executed, for operators running online schema change tools by themselves
+func printExecution(alternator *Alternator, statements []string, commands [][]string) {
+	ePrintln(strings.Repeat("―", width))
+	bPrintln("Statements and commands to execute in order:")
+	bPrintln()
+	withTool := false
+	for i, s := range statements {
+		if commands[i] != nil {
+			fmt.Println(commandString(commands[i]))
+			withTool = true
+		} else {
+			fmt.Println(s)
+		}
+	}
+	if withTool && alternator.DbUri.Password != "" {
+		bPrintln()
+		bPrintln("The commands do not include the password. Add --ask-pass to enter it.")
+	}
+}
+
+// printPartialCompletion shows which statements have been completed and which have not
+func printPartialCompletion(journal *Journal, params ApplyParams) {
+	completed := len(journal.Completed)
+	ePrintln()
+	ePrintln(strings.Repeat("―", width))
+	bPrintf("Failed in the middle! %d of %d statements have been completed:\n", completed, len(journal.Statements))
+	bPrintln()
+	for i, s := range journal.Statements {
+		switch {
+		case i < completed:
+			Green.Fprintf(os.Stderr, "  ✓ %s\n", firstLine(s))
+		case i == completed:
+			Red.Fprintf(os.Stderr, "  ✗ %s\n", firstLine(s))
+		default:
+			ePrintf("    %s\n", firstLine(s))
+		}
+	}
+	bPrintln()
+	bPrintf("The journal is saved in %s.\n", journalPath(params.JournalDir, journal.Fingerprint))
+	bPrintln("Fix the problem and run the same command with --resume to execute the rest of statements.")
+	bPrintln()
+}
+
+func firstLine(s string) string {
+	lines := strings.SplitN(s, "\n", 2)
+	if len(lines) > 1 {
+		return lines[0] + " ..."
+	}
+	return lines[0]
 }
@@ -5,13 +5,17 @@ import (
 	"github.com/kota65535/alternator/lib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 )
 
 var ApplyParam = ApplyParams{
-	AutoApprove: true,
+	AutoApprove:       true,
+	AllowDropDatabase: true,
+	AllowDropTable:    true,
+	AllowDropColumn:   true,
 }
 
 var ApplyTestFixtures = []Fixture{}
@@ -53,3 +57,85 @@ func TestApply(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyPlanFile(t *testing.T) {
+	for _, db := range Databases {
+		fixture := Fixture{db, filepath.Join(RootPath, "db")}
+		t.Run(fixture.Name(), func(t *testing.T) {
+			url := fmt.Sprintf("%s://root@localhost:%d/", fixture.Dialect, fixture.Port)
+			dir := fixture.Dir
+			planFile := filepath.Join(t.TempDir(), "plan.json")
+
+			// when
+			err := prepareDb(dir, fixture.Database)
+			require.NoError(t, err)
+			alt := PlanCmd(testFile(dir, "to.sql", fixture.Database), url, PlanParams{Out: planFile})
+			ApplyCmd(planFile, url, ApplyParam)
+
+			// assert saved statements
+			plan, err := readPlanFile(planFile)
+			require.NoError(t, err)
+			assert.Equal(t, alt.Statements(), plan.Statements)
+
+			// plan after apply should return empty statements
+			alt2 := PlanCmd(testFile(dir, "to.sql", fixture.Database), url, PlanParams{})
+			assert.Nil(t, alt2)
+		})
+	}
+}
+
+func TestWithAlgorithm(t *testing.T) {
+	ddls := map[string]lib.OnlineDdl{
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int;":    {Algorithm: lib.AlgorithmInstant},
+		"ALTER TABLE `db1`.`t1` ADD INDEX (`c1`);":       {Algorithm: lib.AlgorithmInplace, Lock: "NONE"},
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c2` int;": {Algorithm: lib.AlgorithmCopy, Lock: "SHARED"},
+	}
+
+	assert.Equal(t, "ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int, ALGORITHM=INSTANT;",
+		withAlgorithm("ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int;", ddls, AlgorithmPolicyEnforce))
+	assert.Equal(t, "ALTER TABLE `db1`.`t1` ADD INDEX (`c1`), ALGORITHM=INPLACE, LOCK=NONE;",
+		withAlgorithm("ALTER TABLE `db1`.`t1` ADD INDEX (`c1`);", ddls, AlgorithmPolicyNoCopy))
+	assert.Equal(t, "ALTER TABLE `db1`.`t1` MODIFY COLUMN `c2` int;",
+		withAlgorithm("ALTER TABLE `db1`.`t1` MODIFY COLUMN `c2` int;", ddls, AlgorithmPolicyNone))
+	assert.Equal(t, "DROP TABLE `db1`.`t2`;",
+		withAlgorithm("DROP TABLE `db1`.`t2`;", ddls, AlgorithmPolicyEnforce))
+}
+
+func TestCheckDestructions(t *testing.T) {
+	statements := []string{
+		"ALTER TABLE `db1`.`t1` DROP COLUMN `c1`, ADD COLUMN `c2` int;",
+		"DROP TABLE `db1`.`t2`;",
+		"ALTER TABLE `db1`.`t3` ADD COLUMN `c1` int;",
+	}
+	destructions := map[string]lib.Destruction{
+		"ALTER TABLE `db1`.`t1` DROP COLUMN `c1`, ADD COLUMN `c2` int;": lib.DestructionDropColumn,
+		"DROP TABLE `db1`.`t2`;": lib.DestructionDropTable,
+	}
+
+	err := checkDestructions(statements, destructions, ApplyParams{})
+	require.Error(t, err)
+	assert.Equal(t, "refused to apply destructive statements. Use --allow-drop-column, --allow-drop-table to allow them:\n"+
+		"  ALTER TABLE `db1`.`t1` DROP COLUMN `c1`, ADD COLUMN `c2` int;\n"+
+		"  DROP TABLE `db1`.`t2`;", err.Error())
+
+	err = checkDestructions(statements, destructions, ApplyParams{AllowDropColumn: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--allow-drop-table")
+	assert.NotContains(t, err.Error(), "--allow-drop-column")
+
+	err = checkDestructions(statements, destructions, ApplyParams{AllowDropColumn: true, AllowDropTable: true})
+	assert.NoError(t, err)
+}
+
+func TestCheckPasswordStdin(t *testing.T) {
+	passwordStdin = true
+	defer func() { passwordStdin = false }()
+
+	err := checkPasswordStdin(ApplyParams{})
+	assert.ErrorContains(t, err, "--password-stdin requires --auto-approve")
+	assert.NoError(t, checkPasswordStdin(ApplyParams{AutoApprove: true}))
+	assert.NoError(t, checkPasswordStdin(ApplyParams{OscPrint: true}))
+
+	passwordStdin = false
+	assert.NoError(t, checkPasswordStdin(ApplyParams{}))
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/lib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultConfigFile is the project configuration file read by --env flag
+const DefaultConfigFile = "alternator.yaml"
+
+const (
+	ArgSchema = "schema"
+	ArgUrl    = "url"
+)
+
+// Config is a project configuration, which defines named environments
+type Config struct {
+	Environments map[string]*Environment `yaml:"environments"`
+}
+
+// Environment is a set of arguments and flags of commands for a database, such as production or staging.
+// Flags are applied to all commands having them, and flags keyed by command names are applied to the commands only.
+type Environment struct {
+	Url      string                    `yaml:"url"`
+	Schema   string                    `yaml:"schema"`
+	Flags    map[string]any            `yaml:"flags"`
+	Commands map[string]map[string]any `yaml:",inline"`
+}
+
+var (
+	configFile string
+	envName    string
+	// currentEnv is the environment selected by --env flag, or nil if not selected
+	currentEnv *Environment
+)
+
+// readConfig reads the configuration file.
+// Environment variables in the form of $VAR or ${VAR} in string values are expanded, and $$ is replaced with $.
+// They are expanded after parsing, so that their values never change the structure of the file.
+func readConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %s : %w", path, err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %s : %w", path, err)
+	}
+	var missing []string
+	expandNode(&root, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables used in config file are not set: %s", strings.Join(missing, ", "))
+	}
+	var config Config
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %s : %w", path, err)
+	}
+	return &config, nil
+}
+
+// expandNode expands the variables in the string values of the node and its descendants. Mapping keys are kept as
+// they are.
+func expandNode(node *yaml.Node, mapping func(string) string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			node.Value = os.Expand(node.Value, mapping)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if v := node.Content[i]; node.Content[i-1].Value == ArgUrl && v.Kind == yaml.ScalarNode && v.Tag == "!!str" {
+				v.Value = expandUrl(v.Value, mapping)
+				continue
+			}
+			expandNode(node.Content[i], mapping)
+		}
+	default:
+		for _, c := range node.Content {
+			expandNode(c, mapping)
+		}
+	}
+}
+
+// expandUrl expands the variables in the URL. Values in the user info part are escaped, so that passwords containing
+// characters such as "@", ":" and "#" are not parsed as delimiters of the URL.
+func expandUrl(s string, mapping func(string) string) string {
+	_, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return os.Expand(s, mapping)
+	}
+	authority, _, _ := strings.Cut(rest, "/")
+	i := strings.LastIndex(authority, "@")
+	if i < 0 {
+		return os.Expand(s, mapping)
+	}
+	escaped := func(name string) string {
+		v := mapping(name)
+		if name == "$" {
+			return v
+		}
+		return url.User(v).String()
+	}
+	scheme := s[:len(s)-len(rest)]
+	return os.Expand(scheme, mapping) + os.Expand(rest[:i], escaped) + os.Expand(rest[i:], mapping)
+}
+
+// loadEnv reads the configuration file and applies flags of the environment to the command.
+// Flags given in the command line take precedence over those of the environment.
+func loadEnv(cmd *cobra.Command) error {
+	if envName == "" {
+		return nil
+	}
+	config, err := readConfig(configFile)
+	if err != nil {
+		return err
+	}
+	env, ok := config.Environments[envName]
+	if !ok {
+		return fmt.Errorf("environment not found in config file: %s", envName)
+	}
+	for name := range env.Commands {
+		if lib.Find(cmd.Root().Commands(), func(c *cobra.Command) bool { return c.Name() == name }) < 0 {
+			return fmt.Errorf("unknown command in environment %s: %s", envName, name)
+		}
+	}
+	if err := setEnvFlags(cmd.Flags(), env.Flags, false); err != nil {
+		return err
+	}
+	if err := setEnvFlags(cmd.Flags(), env.Commands[cmd.Name()], true); err != nil {
+		return err
+	}
+	currentEnv = env
+	return nil
+}
+
+// setEnvFlags sets the flag values unless they are given in the command line.
+// Flags the command does not have are ignored if not strict.
+func setEnvFlags(flags *pflag.FlagSet, values map[string]any, strict bool) error {
+	names := []string{}
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := flags.Lookup(name)
+		if f == nil {
+			if strict {
+				return fmt.Errorf("unknown flag in environment %s: %s", envName, name)
+			}
+			continue
+		}
+		if f.Changed {
+			continue
+		}
+		if err := flags.Set(name, flagValue(values[name])); err != nil {
+			return fmt.Errorf("invalid flag value in environment %s: %s : %w", envName, name, err)
+		}
+	}
+	return nil
+}
+
+// flagValue converts a YAML value to a flag value. Lists are joined by commas, and maps are joined as key=value pairs.
+func flagValue(v any) string {
+	switch t := v.(type) {
+	case []any:
+		var strs []string
+		for _, e := range t {
+			strs = append(strs, flagValue(e))
+		}
+		return strings.Join(strs, ",")
+	case map[string]any:
+		var strs []string
+		for k, e := range t {
+			strs = append(strs, fmt.Sprintf("%s=%s", k, flagValue(e)))
+		}
+		sort.Strings(strs)
+		return strings.Join(strs, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// envArgs returns a validator of positional arguments, which allows omitting them if an environment is selected
+func envArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if envName != "" {
+			return cobra.RangeArgs(0, n)(cmd, args)
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	}
+}
+
+// argsWithEnv fills the omitted positional arguments of the given kinds by those of the selected environment
+func argsWithEnv(args []string, kinds ...string) ([]string, error) {
+	ret := append([]string{}, args...)
+	for i := len(args); i < len(kinds); i++ {
+		if currentEnv == nil {
+			return nil, fmt.Errorf("requires %d arg(s), only received %d", len(kinds), len(args))
+		}
+		v := ""
+		switch kinds[i] {
+		case ArgSchema:
+			v = currentEnv.Schema
+		case ArgUrl:
+			v = currentEnv.Url
+		}
+		if v == "" {
+			return nil, fmt.Errorf("%s is not defined in environment %s", kinds[i], envName)
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
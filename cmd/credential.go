@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables of the database password, in order of precedence
+var PasswordEnvVars = []string{"ALTERNATOR_DB_PASSWORD", "MYSQL_PWD"}
+
+var (
+	// defaultsFile is the option file read instead of ~/.my.cnf
+	defaultsFile string
+	// loginPath is the group of option files read in addition to [client]
+	loginPath string
+	// passwordStdin reads the password from stdin
+	passwordStdin bool
+	// stdinPassword is the password read from stdin, which is cached because stdin can be read only once
+	stdinPassword *string
+)
+
+// fillCredentials sets the user and the password not given in the URI.
+// The password is taken from the first of stdin (if --password-stdin is given), the environment variables and
+// the option files, and the user is taken from the option files.
+func fillCredentials(dbUri *DatabaseUri) error {
+	options, err := readOptionFiles()
+	if err != nil {
+		return err
+	}
+	if dbUri.User == "" {
+		dbUri.User = options["user"]
+	}
+	if dbUri.Password != "" {
+		return nil
+	}
+	if passwordStdin {
+		if stdinPassword == nil {
+			b, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read password from stdin : %w", err)
+			}
+			s := strings.TrimRight(string(b), "\r\n")
+			stdinPassword = &s
+		}
+		dbUri.Password = *stdinPassword
+		return nil
+	}
+	for _, k := range PasswordEnvVars {
+		if v, ok := os.LookupEnv(k); ok {
+			dbUri.Password = v
+			return nil
+		}
+	}
+	dbUri.Password = options["password"]
+	return nil
+}
+
+// readOptionFiles reads MySQL option files and returns the options in [client] group and the group of --login-path.
+// ~/.my.cnf (or the file given by --defaults-file) is read first, and then ~/.mylogin.cnf overrides it.
+func readOptionFiles() (map[string]string, error) {
+	groups := []string{"client"}
+	if loginPath != "" {
+		groups = append(groups, loginPath)
+	}
+	options := map[string]string{}
+
+	home, _ := os.UserHomeDir()
+	path := defaultsFile
+	if path == "" {
+		path = filepath.Join(home, ".my.cnf")
+	}
+	f, err := os.Open(path)
+	if err == nil {
+		defer f.Close()
+		if err := parseOptionFile(f, groups, options); err != nil {
+			return nil, fmt.Errorf("failed to read option file: %s : %w", path, err)
+		}
+	} else if defaultsFile != "" {
+		return nil, fmt.Errorf("failed to read option file: %s : %w", path, err)
+	}
+
+	path = os.Getenv("MYSQL_TEST_LOGIN_FILE")
+	if path == "" {
+		path = filepath.Join(home, ".mylogin.cnf")
+	}
+	b, err := os.ReadFile(path)
+	if err == nil {
+		b, err = decryptLoginFile(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read login path file: %s : %w", path, err)
+		}
+		if err := parseOptionFile(bytes.NewReader(b), groups, options); err != nil {
+			return nil, fmt.Errorf("failed to read login path file: %s : %w", path, err)
+		}
+	}
+	return options, nil
+}
+
+// parseOptionFile parses an option file in INI format, and sets the options in the groups.
+// Later groups take precedence over earlier ones regardless of the order in the file.
+func parseOptionFile(r io.Reader, groups []string, options map[string]string) error {
+	values := make([]map[string]string, len(groups))
+	for i := range values {
+		values[i] = map[string]string{}
+	}
+	group := -1
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!':
+			continue
+		case line[0] == '[' && line[len(line)-1] == ']':
+			group = -1
+			for i, g := range groups {
+				if strings.TrimSpace(line[1:len(line)-1]) == g {
+					group = i
+				}
+			}
+			continue
+		}
+		if group < 0 {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.TrimSpace(key), "_", "-")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[group][key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, v := range values {
+		for k, e := range v {
+			options[k] = e
+		}
+	}
+	return nil
+}
+
+// decryptLoginFile decrypts ~/.mylogin.cnf written by mysql_config_editor.
+// The file consists of 4 unused bytes, a 20 bytes key, and lines encrypted by AES-128-ECB each prefixed by its length.
+func decryptLoginFile(b []byte) ([]byte, error) {
+	if len(b) < 24 {
+		return nil, errors.New("file is too short")
+	}
+	key := make([]byte, 16)
+	for i, c := range b[4:24] {
+		key[i%16] ^= c
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []byte
+	rest := b[24:]
+	for len(rest) >= 4 {
+		n := int(binary.LittleEndian.Uint32(rest[:4]))
+		rest = rest[4:]
+		if n > len(rest) || n%aes.BlockSize != 0 {
+			return nil, errors.New("file is corrupted")
+		}
+		line := make([]byte, n)
+		for i := 0; i < n; i += aes.BlockSize {
+			block.Decrypt(line[i:i+aes.BlockSize], rest[i:i+aes.BlockSize])
+		}
+		rest = rest[n:]
+		// Remove PKCS#7 padding
+		if n > 0 {
+			pad := int(line[n-1])
+			if pad > 0 && pad <= aes.BlockSize {
+				line = line[:n-pad]
+			}
+		}
+		ret = append(ret, line...)
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			ret = append(ret, '\n')
+		}
+	}
+	return ret, nil
+}
+
+// canPromptPassword returns true if the connection is denied for the missing password and the user can type it
+func canPromptPassword(dbUri *DatabaseUri, err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return dbUri.Password == "" && errors.As(err, &mysqlErr) && mysqlErr.Number == 1045 &&
+		term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptPassword reads the password from the terminal without echo
+func promptPassword(dbUri *DatabaseUri) (string, error) {
+	ePrintf("Password for %s@%s: ", dbUri.User, dbUri.Addr())
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	ePrintln()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password : %w", err)
+	}
+	return string(b), nil
+}
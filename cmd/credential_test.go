@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFillCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MYSQL_TEST_LOGIN_FILE", filepath.Join(home, ".mylogin.cnf"))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".my.cnf"), []byte(`
+# comment
+[mysqld]
+user = mysql
+
+[client]
+user = bob
+password = "my cnf"
+
+[prod]
+password = prod
+`), 0600))
+
+	// URI takes precedence
+	dbUri := &DatabaseUri{User: "alice", Password: "secret"}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "alice", dbUri.User)
+	assert.Equal(t, "secret", dbUri.Password)
+
+	dbUri = &DatabaseUri{}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "bob", dbUri.User)
+	assert.Equal(t, "my cnf", dbUri.Password)
+
+	// Environment variables take precedence over option files
+	t.Setenv("MYSQL_PWD", "mysql pwd")
+	dbUri = &DatabaseUri{}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "mysql pwd", dbUri.Password)
+
+	t.Setenv("ALTERNATOR_DB_PASSWORD", "alternator pwd")
+	dbUri = &DatabaseUri{}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "alternator pwd", dbUri.Password)
+	os.Unsetenv("ALTERNATOR_DB_PASSWORD")
+	os.Unsetenv("MYSQL_PWD")
+
+	// Login path
+	loginPath = "prod"
+	defer func() { loginPath = "" }()
+	dbUri = &DatabaseUri{}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "bob", dbUri.User)
+	assert.Equal(t, "prod", dbUri.Password)
+
+	writeLoginFile(t, filepath.Join(home, ".mylogin.cnf"), "[client]\nuser = \"carol\"\n[prod]\npassword = \"login path\"\n")
+	dbUri = &DatabaseUri{}
+	require.NoError(t, fillCredentials(dbUri))
+	assert.Equal(t, "carol", dbUri.User)
+	assert.Equal(t, "login path", dbUri.Password)
+
+	// Defaults file
+	defaultsFile = filepath.Join(home, "missing.cnf")
+	defer func() { defaultsFile = "" }()
+	assert.ErrorContains(t, fillCredentials(&DatabaseUri{}), "failed to read option file")
+}
+
+// writeLoginFile writes the content encrypted in the same way as mysql_config_editor
+func writeLoginFile(t *testing.T, path string, content string) {
+	key := []byte("0123456789abcdefghij")
+	aesKey := make([]byte, 16)
+	for i, c := range key {
+		aesKey[i%16] ^= c
+	}
+	block, err := aes.NewCipher(aesKey)
+	require.NoError(t, err)
+
+	b := append([]byte{0, 0, 0, 0}, key...)
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		pad := aes.BlockSize - len(line)%aes.BlockSize
+		plain := append([]byte(line), []byte(strings.Repeat(string(rune(pad)), pad))...)
+		cipher := make([]byte, len(plain))
+		for i := 0; i < len(plain); i += aes.BlockSize {
+			block.Encrypt(cipher[i:i+aes.BlockSize], plain[i:i+aes.BlockSize])
+		}
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(cipher)))
+		b = append(b, cipher...)
+	}
+	require.NoError(t, os.WriteFile(path, b, 0600))
+}
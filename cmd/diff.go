@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/spf13/cobra"
+	"net/url"
+	"strings"
+)
+
+//go:embed diff.tmpl
+var diffUsage string
+
+// DefaultCharsetToCollation is the default collations of character sets in MySQL 8.0,
+// used to compare schema files without database access
+var DefaultCharsetToCollation = map[string]string{
+	"armscii8": "armscii8_general_ci",
+	"ascii":    "ascii_general_ci",
+	"big5":     "big5_chinese_ci",
+	"binary":   "binary",
+	"cp1250":   "cp1250_general_ci",
+	"cp1251":   "cp1251_general_ci",
+	"cp1256":   "cp1256_general_ci",
+	"cp1257":   "cp1257_general_ci",
+	"cp850":    "cp850_general_ci",
+	"cp852":    "cp852_general_ci",
+	"cp866":    "cp866_general_ci",
+	"cp932":    "cp932_japanese_ci",
+	"dec8":     "dec8_swedish_ci",
+	"eucjpms":  "eucjpms_japanese_ci",
+	"euckr":    "euckr_korean_ci",
+	"gb18030":  "gb18030_chinese_ci",
+	"gb2312":   "gb2312_chinese_ci",
+	"gbk":      "gbk_chinese_ci",
+	"geostd8":  "geostd8_general_ci",
+	"greek":    "greek_general_ci",
+	"hebrew":   "hebrew_general_ci",
+	"hp8":      "hp8_english_ci",
+	"keybcs2":  "keybcs2_general_ci",
+	"koi8r":    "koi8r_general_ci",
+	"koi8u":    "koi8u_general_ci",
+	"latin1":   "latin1_swedish_ci",
+	"latin2":   "latin2_general_ci",
+	"latin5":   "latin5_turkish_ci",
+	"latin7":   "latin7_general_ci",
+	"macce":    "macce_general_ci",
+	"macroman": "macroman_general_ci",
+	"sjis":     "sjis_japanese_ci",
+	"swe7":     "swe7_swedish_ci",
+	"tis620":   "tis620_thai_ci",
+	"ucs2":     "ucs2_general_ci",
+	"ujis":     "ujis_japanese_ci",
+	"utf16":    "utf16_general_ci",
+	"utf16le":  "utf16le_general_ci",
+	"utf32":    "utf32_general_ci",
+	"utf8":     "utf8_general_ci",
+	"utf8mb3":  "utf8mb3_general_ci",
+	"utf8mb4":  "utf8mb4_0900_ai_ci",
+}
+
+type DiffParams struct {
+	Format                 string
+	CharacterSetServer     string
+	CollationServer        string
+	DefaultTableEncryption bool
+	CharsetToCollation     map[string]string
+	DatabaseMap            map[string]string
+	SplitStatements        bool
+}
+
+func init() {
+	var params DiffParams
+
+	c := &cobra.Command{
+		Use:   "diff <from-schema-file|database-url-a> <to-schema-file|database-url-b>",
+		Short: "Show the schema changes between two schema files or two databases.",
+		Long:  "Show the schema changes between two schema files or two databases.",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkFormat(params.Format)
+			DiffCmd(args[0], args[1], params)
+		},
+	}
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text or json)")
+	c.Flags().StringVar(&params.CharacterSetServer, "character-set-server", "utf8mb4", "Default character set of the server")
+	c.Flags().StringVar(&params.CollationServer, "collation-server", "utf8mb4_0900_ai_ci", "Default collation of the server")
+	c.Flags().BoolVar(&params.DefaultTableEncryption, "default-table-encryption", false, "Default encryption of the server")
+	c.Flags().StringToStringVar(&params.CharsetToCollation, "charset-collation", nil, "Default collation of character sets (e.g. utf8mb4=utf8mb4_general_ci)")
+	c.Flags().StringToStringVar(&params.DatabaseMap, "map-database", nil, "Database names of A mapped to those of B (e.g. app=app_staging)")
+	c.Flags().BoolVar(&params.SplitStatements, "split-statements", false, "Execute each clause by its own ALTER TABLE statement instead of merging clauses altering the same table")
+	rootCmd.AddCommand(c)
+	c.SetUsageTemplate(diffUsage)
+}
+
+func DiffCmd(from string, to string, params DiffParams) *lib.DatabaseAlterations {
+	var fromSchemas, toSchemas []*lib.Schema
+	var err error
+	switch {
+	case isDatabaseUri(from) && isDatabaseUri(to):
+		// Make database B look like database A
+		fromSchemas, toSchemas, err = fetchSchemasToCompare(to, from, params.DatabaseMap)
+		cobra.CheckErr(err)
+	case !isDatabaseUri(from) && !isDatabaseUri(to):
+		config := params.GlobalConfig()
+		fromSchemas, err = readSchemas(from, config)
+		cobra.CheckErr(err)
+		toSchemas, err = readSchemas(to, config)
+		cobra.CheckErr(err)
+	default:
+		cobra.CheckErr(fmt.Errorf("cannot compare a schema file with a database. Use plan command instead"))
+	}
+
+	fromSchemas = sortRemoteSchema(fromSchemas, toSchemas)
+	alt := lib.NewDatabaseAlterationsWithRenameThreshold(fromSchemas, toSchemas, renameThreshold)
+	alt.SetSplitStatements(params.SplitStatements)
+
+	if params.Format == FormatJson {
+		printJson(lib.NewReport(alt))
+		if len(alt.Statements()) == 0 {
+			return nil
+		}
+		return alt
+	}
+
+	if !printAlterations(alt) {
+		return nil
+	}
+	return alt
+}
+
+// GlobalConfig returns the server configuration which schema files are supposed to be applied with
+func (r DiffParams) GlobalConfig() *parser.GlobalConfig {
+	charsetToCollation := map[string]string{}
+	for k, v := range DefaultCharsetToCollation {
+		charsetToCollation[k] = v
+	}
+	for k, v := range r.CharsetToCollation {
+		charsetToCollation[k] = v
+	}
+	encryption := "'N'"
+	if r.DefaultTableEncryption {
+		encryption = "'Y'"
+	}
+	return &parser.GlobalConfig{
+		CharacterSetServer:   r.CharacterSetServer,
+		CharacterSetDatabase: r.CharacterSetServer,
+		CollationServer:      r.CollationServer,
+		CharsetToCollation:   charsetToCollation,
+		Encryption:           encryption,
+	}
+}
+
+func readSchemas(path string, config *parser.GlobalConfig) ([]*lib.Schema, error) {
+	sources, err := readSchemaFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := lib.NewSchemasFromSources(sources, config, hashset.New())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shema : %w", err)
+	}
+	return filter.Apply(schemas), nil
+}
+
+// fetchSchemasToCompare fetches schemas of database B and A, with database names of A renamed to those of B.
+// Each schema is read with the global config of its own server.
+func fetchSchemasToCompare(uriB string, uriA string, databaseMap map[string]string) ([]*lib.Schema, []*lib.Schema, error) {
+	dbUriB, err := NewDatabaseUri(uriB)
+	if err != nil {
+		return nil, nil, err
+	}
+	dbUriA, err := NewDatabaseUri(uriA)
+	if err != nil {
+		return nil, nil, err
+	}
+	schemasB, err := fetchSchemas(dbUriB)
+	if err != nil {
+		return nil, nil, err
+	}
+	schemasA, err := fetchSchemas(dbUriA)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := map[string]string{}
+	if dbUriA.DbName != "" && dbUriB.DbName != "" {
+		names[dbUriA.DbName] = dbUriB.DbName
+	}
+	for k, v := range databaseMap {
+		names[k] = v
+	}
+	return schemasB, lib.RenameDatabases(schemasA, names), nil
+}
+
+func fetchSchemas(dbUri *DatabaseUri) ([]*lib.Schema, error) {
+	alternator, err := NewAlternator(dbUri)
+	if err != nil {
+		return nil, err
+	}
+	defer alternator.Close()
+
+	schemas, err := alternator.FetchSchemas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schemas from %s : %w", dbUri.Addr(), err)
+	}
+	return schemas, nil
+}
+
+// isDatabaseUri returns true if the string is a database URL rather than a schema file path.
+// The transport following "+" in the scheme is ignored, as NewDatabaseUri does.
+func isDatabaseUri(s string) bool {
+	p, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	dialect, _, _ := strings.Cut(p.Scheme, "+")
+	return SupportedDialects.Contains(dialect)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := filepath.Join(RootPath, "db")
+	alt := DiffCmd(filepath.Join(dir, "from.sql"), filepath.Join(dir, "to.sql"), DiffParams{
+		Format:             FormatText,
+		CharacterSetServer: "utf8mb4",
+		CollationServer:    "utf8mb4_0900_ai_ci",
+	})
+	require.NotNil(t, alt)
+
+	s, err := getAlter(dir, Databases[1])
+	require.NoError(t, err)
+	assert.Equal(t, s, strings.Join(alt.Statements(), "\n"))
+
+	// no change
+	alt = DiffCmd(filepath.Join(dir, "to.sql"), filepath.Join(dir, "to.sql"), DiffParams{Format: FormatText})
+	assert.Nil(t, alt)
+}
+
+func TestDiffDatabases(t *testing.T) {
+	dir := filepath.Join(RootPath, "db")
+	for _, db := range Databases {
+		fixture := Fixture{db, dir}
+		t.Run(fixture.Name(), func(t *testing.T) {
+			if lib.Contains(Skipped, fixture.Name()) {
+				t.Skip()
+			}
+			url := fmt.Sprintf("%s://root@localhost:%d/", fixture.Dialect, fixture.Port)
+
+			err := prepareDb(dir, fixture.Database)
+			require.NoError(t, err)
+
+			// no change
+			alt := DiffCmd(url+"db1", url+"db1", DiffParams{Format: FormatText})
+			assert.Nil(t, alt)
+
+			// database names are mapped
+			alt = DiffCmd(url+"db1", url+"db4", DiffParams{Format: FormatText})
+			require.NotNil(t, alt)
+			for _, s := range alt.Statements() {
+				assert.NotContains(t, s, "`db1`")
+			}
+		})
+	}
+}
+
+func TestDiffFiltered(t *testing.T) {
+	filter = lib.Filter{
+		ExcludeDatabases: []string{"db4"},
+		ExcludeTables:    []string{"db1.t12", "t2?"},
+	}
+	defer func() { filter = lib.Filter{} }()
+
+	dir := filepath.Join(RootPath, "db")
+	alt := DiffCmd(filepath.Join(dir, "from.sql"), filepath.Join(dir, "to.sql"), DiffParams{
+		Format:             FormatText,
+		CharacterSetServer: "utf8mb4",
+		CollationServer:    "utf8mb4_0900_ai_ci",
+	})
+	require.NotNil(t, alt)
+
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t11` RENAME TO `db1`.`t13`;",
+		"ALTER DATABASE `db2` DEFAULT COLLATE = utf8mb4_bin;",
+		"CREATE DATABASE `db3`\n    DEFAULT COLLATE = utf8mb4_bin;",
+		"CREATE TABLE `db3`.`t32`\n(\n    `varchar1` varchar(32) NOT NULL,\n    PRIMARY KEY (`varchar1`)\n);",
+		"CREATE TABLE `db3`.`t31`\n(\n    `int1` int NOT NULL,\n    PRIMARY KEY (`int1`)\n);",
+	}, alt.Statements())
+}
+
+func TestIsDatabaseUri(t *testing.T) {
+	assert.True(t, isDatabaseUri("mysql://root@localhost:3306/db1"))
+	assert.True(t, isDatabaseUri("mysql+tcp://root@localhost:3306/db1"))
+	assert.True(t, isDatabaseUri("mysql+unix://root@/var/run/mysqld/mysqld.sock/db1"))
+	assert.False(t, isDatabaseUri("postgres://root@localhost:5432/db1"))
+	assert.False(t, isDatabaseUri(filepath.Join(RootPath, "db", "to.sql")))
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/lib"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed generate_migration.tmpl
+var generateMigrationUsage string
+
+const (
+	MigrationFormatGolangMigrate = "golang-migrate"
+	MigrationFormatFlyway        = "flyway"
+	MigrationFormatGoose         = "goose"
+	MigrationFormatDbmate        = "dbmate"
+)
+
+var SupportedMigrationFormats = hashset.New(
+	MigrationFormatGolangMigrate,
+	MigrationFormatFlyway,
+	MigrationFormatGoose,
+	MigrationFormatDbmate,
+)
+
+// MigrationVersionLayout is the timestamp layout used as migration versions
+const MigrationVersionLayout = "20060102150405"
+
+type GenerateMigrationParams struct {
+	Format          string
+	Dir             string
+	Name            string
+	SplitStatements bool
+}
+
+func init() {
+	var params GenerateMigrationParams
+
+	c := &cobra.Command{
+		Use:   "generate-migration <schema-file> <database-url>",
+		Short: "Generate a migration file of the remote database schema changes required by the local schema file.",
+		Long:  "Generate a migration file of the remote database schema changes required by the local schema file.",
+		Args:  envArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			args, err := argsWithEnv(args, ArgSchema, ArgUrl)
+			cobra.CheckErr(err)
+			if !SupportedMigrationFormats.Contains(params.Format) {
+				cobra.CheckErr(fmt.Errorf("unsupported migration format: %s", params.Format))
+			}
+			GenerateMigrationCmd(args[0], args[1], params)
+		},
+	}
+	c.Flags().StringVar(&params.Format, "format", MigrationFormatGolangMigrate, "Migration tool format (golang-migrate, flyway, goose or dbmate)")
+	c.Flags().StringVar(&params.Dir, "dir", "migrations", "Directory to write the migration file")
+	c.Flags().StringVar(&params.Name, "name", "alternator", "Name of the migration")
+	c.Flags().BoolVar(&params.SplitStatements, "split-statements", false, "Execute each clause by its own ALTER TABLE statement instead of merging clauses altering the same table")
+	rootCmd.AddCommand(c)
+	c.SetUsageTemplate(generateMigrationUsage)
+}
+
+func GenerateMigrationCmd(path string, uri string, params GenerateMigrationParams) []string {
+	dbUri, err := NewDatabaseUri(uri)
+	cobra.CheckErr(err)
+
+	alternator, err := NewAlternator(dbUri)
+	cobra.CheckErr(err)
+	defer alternator.Close()
+
+	alt, _, _, err := alternator.GetAlterationsFromFile(path)
+	cobra.CheckErr(err)
+	alt.SetSplitStatements(params.SplitStatements)
+
+	if !printAlterations(alt) {
+		return nil
+	}
+	downStatements := alt.Reverse().Statements()
+	if !printWithoutReverse(alt) {
+		downStatements = downMigrationStub(alt)
+		Yellow.Fprintln(os.Stderr, "The down migration is written without statements. Write them by yourself.")
+	}
+
+	migrations, err := migrationFiles(params.Format, params.Name, time.Now().UTC(), alt.Statements(), downStatements)
+	cobra.CheckErr(err)
+
+	err = os.MkdirAll(params.Dir, 0755)
+	cobra.CheckErr(err)
+	bPrintln()
+	var files []string
+	for _, m := range migrations {
+		file := filepath.Join(params.Dir, m.Name)
+		err = os.WriteFile(file, []byte(m.Body), 0644)
+		cobra.CheckErr(err)
+		bPrintf("Wrote the migration file to %s.\n", file)
+		files = append(files, file)
+	}
+	printIrreversible(alt)
+
+	return files
+}
+
+// downMigrationStub returns comments listing the changes which cannot be reverted, instead of down statements
+func downMigrationStub(alt *lib.DatabaseAlterations) []string {
+	ret := []string{"-- The following changes cannot be reverted, since the names of their keys or constraints are given by the server:"}
+	for _, r := range lib.NewReport(alt).WithoutReverse() {
+		ret = append(ret, fmt.Sprintf("--   - %s %s %s", r.Kind, strings.ReplaceAll(r.Type, "_", " "), r.Name))
+	}
+	return ret
+}
+
+type MigrationFile struct {
+	Name string
+	Body string
+}
+
+var nonWordChars = regexp.MustCompile(`\W+`)
+
+// migrationFiles returns migration files in the conventions of the migration tool.
+// Down statements are written into a separate file or a section of the same file depending on the tool.
+func migrationFiles(format string, name string, t time.Time, upStatements []string, downStatements []string) ([]*MigrationFile, error) {
+	version := t.Format(MigrationVersionLayout)
+	name = strings.Trim(nonWordChars.ReplaceAllString(name, "_"), "_")
+	up := strings.Join(upStatements, "\n") + "\n"
+	down := strings.Join(downStatements, "\n") + "\n"
+
+	switch format {
+	case MigrationFormatGolangMigrate:
+		return []*MigrationFile{
+			{fmt.Sprintf("%s_%s.up.sql", version, name), up},
+			{fmt.Sprintf("%s_%s.down.sql", version, name), down},
+		}, nil
+	case MigrationFormatFlyway:
+		// Undo migrations are supported by Flyway Teams
+		return []*MigrationFile{
+			{fmt.Sprintf("V%s__%s.sql", version, name), up},
+			{fmt.Sprintf("U%s__%s.sql", version, name), down},
+		}, nil
+	case MigrationFormatGoose:
+		return []*MigrationFile{
+			{fmt.Sprintf("%s_%s.sql", version, name), "-- +goose Up\n" + up + "\n-- +goose Down\n" + down},
+		}, nil
+	case MigrationFormatDbmate:
+		return []*MigrationFile{
+			{fmt.Sprintf("%s_%s.sql", version, name), "-- migrate:up\n" + up + "\n-- migrate:down\n" + down},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported migration format: %s", format)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	up := []string{"ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int;", "DROP TABLE `db1`.`t2`;"}
+	down := []string{"CREATE TABLE `db1`.`t2`\n(\n    `id` int\n);", "ALTER TABLE `db1`.`t1` DROP COLUMN `c1`;"}
+	upBody := "ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int;\nDROP TABLE `db1`.`t2`;\n"
+	downBody := "CREATE TABLE `db1`.`t2`\n(\n    `id` int\n);\nALTER TABLE `db1`.`t1` DROP COLUMN `c1`;\n"
+
+	cases := []struct {
+		format string
+		files  []*MigrationFile
+	}{
+		{
+			MigrationFormatGolangMigrate,
+			[]*MigrationFile{
+				{"20230405060708_add_c1.up.sql", upBody},
+				{"20230405060708_add_c1.down.sql", downBody},
+			},
+		},
+		{
+			MigrationFormatFlyway,
+			[]*MigrationFile{
+				{"V20230405060708__add_c1.sql", upBody},
+				{"U20230405060708__add_c1.sql", downBody},
+			},
+		},
+		{
+			MigrationFormatGoose,
+			[]*MigrationFile{
+				{"20230405060708_add_c1.sql", "-- +goose Up\n" + upBody + "\n-- +goose Down\n" + downBody},
+			},
+		},
+		{
+			MigrationFormatDbmate,
+			[]*MigrationFile{
+				{"20230405060708_add_c1.sql", "-- migrate:up\n" + upBody + "\n-- migrate:down\n" + downBody},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			files, err := migrationFiles(c.format, "add c1", now, up, down)
+			require.NoError(t, err)
+			assert.Equal(t, c.files, files)
+		})
+	}
+
+	_, err := migrationFiles("liquibase", "add c1", now, up, down)
+	assert.ErrorContains(t, err, "unsupported migration format: liquibase")
+}
+
+func TestDownMigrationStub(t *testing.T) {
+	config := &parser.GlobalConfig{CharsetToCollation: DefaultCharsetToCollation}
+	alternator := &Alternator{DbUri: &DatabaseUri{}, GlobalConfig: config, Filter: &lib.Filter{}}
+	remoteSchemas, err := alternator.ReadSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int);`)
+	require.NoError(t, err)
+	localSchemas, err := alternator.ReadSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int, c2 int, INDEX (c1));`)
+	require.NoError(t, err)
+	alt := lib.NewDatabaseAlterations(remoteSchemas, localSchemas)
+
+	// the up migration is written as usual, but the down migration lists the changes without reverse statements
+	files, err := migrationFiles(MigrationFormatGolangMigrate, "add c2", time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC),
+		alt.Statements(), downMigrationStub(alt))
+	require.NoError(t, err)
+	assert.Equal(t, []*MigrationFile{
+		{"20230405060708_add_c2.up.sql", "ALTER TABLE `db1`.`t1` ADD COLUMN `c2` int AFTER `c1`, ADD INDEX (`c1`);\n"},
+		{"20230405060708_add_c2.down.sql", "-- The following changes cannot be reverted, since the names of their keys or constraints are given by the server:\n" +
+			"--   - added index `c1`\n"},
+	}, files)
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/spf13/cobra"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+//go:embed history.tmpl
+var historyUsage string
+
+// DefaultHistoryTable is the table recording applies, which is created in the target database
+const DefaultHistoryTable = "alternator_history"
+
+// historyTable is the name of the history table, either "table" in the database of the URL or "database.table".
+// Empty name disables recording histories.
+var historyTable string
+
+const (
+	HistoryStatusSucceeded = "succeeded"
+	HistoryStatusFailed    = "failed"
+	// HistoryStatusSkipped is the status of statements completed by the previous apply before resuming
+	HistoryStatusSkipped = "skipped"
+	// HistoryStatusPending is the status of statements not executed because of the failure of preceding statements
+	HistoryStatusPending = "pending"
+)
+
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// History is a record of an apply
+type History struct {
+	Id int64 `json:"id"`
+	// Fingerprint of the schema file, or the plan for plan files
+	SchemaHash string `json:"schema_hash"`
+	Status     string `json:"status"`
+	// OS user and host executing the apply
+	User string `json:"user"`
+	Host string `json:"host"`
+	// Times in UTC
+	StartedAt  string              `json:"started_at"`
+	FinishedAt string              `json:"finished_at"`
+	Statements []*HistoryStatement `json:"statements"`
+}
+
+type HistoryStatement struct {
+	Statement string `json:"statement"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type HistoryParams struct {
+	Format string
+	Id     int64
+	Limit  int
+}
+
+func init() {
+	var params HistoryParams
+
+	c := &cobra.Command{
+		Use:   "history <database-url>",
+		Short: "Show the history of applies to the remote database.",
+		Long:  "Show the history of applies to the remote database.",
+		Args:  envArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			args, err := argsWithEnv(args, ArgUrl)
+			cobra.CheckErr(err)
+			checkFormat(params.Format)
+			HistoryCmd(args[0], params)
+		},
+	}
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text or json)")
+	c.Flags().Int64Var(&params.Id, "id", 0, "Show the statements of the apply of the ID")
+	c.Flags().IntVar(&params.Limit, "limit", 20, "Maximum number of applies to list")
+	rootCmd.AddCommand(c)
+	c.SetUsageTemplate(historyUsage)
+}
+
+func HistoryCmd(uri string, params HistoryParams) []*History {
+	dbUri, err := NewDatabaseUri(uri)
+	cobra.CheckErr(err)
+
+	alternator, err := NewAlternator(dbUri)
+	cobra.CheckErr(err)
+	defer alternator.Close()
+
+	if params.Id != 0 {
+		history, err := alternator.GetHistory(params.Id)
+		cobra.CheckErr(err)
+		if history == nil {
+			cobra.CheckErr(fmt.Errorf("apply history not found: %d", params.Id))
+		}
+		if params.Format == FormatJson {
+			printJson(history)
+		} else {
+			printHistory(history)
+		}
+		return []*History{history}
+	}
+
+	histories, err := alternator.ListHistories(params.Limit)
+	cobra.CheckErr(err)
+	if params.Format == FormatJson {
+		printJson(histories)
+		return histories
+	}
+	if len(histories) == 0 {
+		bPrintln("No apply history found.")
+		return histories
+	}
+	for _, h := range histories {
+		printHistorySummary(h)
+	}
+	return histories
+}
+
+// NewHistory returns a history of executing the statements of the journal, which starts now.
+// Statements completed before resuming are marked as skipped.
+func NewHistory(journal *Journal) *History {
+	statements := []*HistoryStatement{}
+	for i, s := range journal.Statements {
+		status := HistoryStatusPending
+		if i < len(journal.Completed) {
+			status = HistoryStatusSkipped
+		}
+		statements = append(statements, &HistoryStatement{Statement: s, Status: status})
+	}
+	userName := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		userName = u.Username
+	}
+	host, _ := os.Hostname()
+	return &History{
+		SchemaHash: journal.Source,
+		User:       userName,
+		Host:       host,
+		StartedAt:  time.Now().UTC().Format(historyTimeFormat),
+		Statements: statements,
+	}
+}
+
+// Succeed marks the i-th statement as succeeded
+func (r *History) Succeed(i int) {
+	r.Statements[i].Status = HistoryStatusSucceeded
+}
+
+// Fail marks the i-th statement as failed by the error
+func (r *History) Fail(i int, err error) {
+	r.Statements[i].Status = HistoryStatusFailed
+	r.Statements[i].Error = err.Error()
+}
+
+// Finish sets the end time and the status, which is failed if any statement has failed
+func (r *History) Finish() {
+	r.FinishedAt = time.Now().UTC().Format(historyTimeFormat)
+	r.Status = HistoryStatusSucceeded
+	for _, s := range r.Statements {
+		if s.Status == HistoryStatusFailed {
+			r.Status = HistoryStatusFailed
+		}
+	}
+}
+
+// historyTableName returns the quoted name of the history table, or empty string if histories are not recorded.
+// Unqualified names are resolved in the database of the URL, and not recorded if the URL has no database name.
+func historyTableName(dbUri *DatabaseUri) string {
+	dbName, tableName := historyTableOf(dbUri)
+	if dbName == "" || tableName == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`.`%s`", dbName, tableName)
+}
+
+func historyTableOf(dbUri *DatabaseUri) (string, string) {
+	if historyTable == "" {
+		return "", ""
+	}
+	if dbName, tableName, ok := strings.Cut(historyTable, "."); ok {
+		return dbName, tableName
+	}
+	return dbUri.DbName, historyTable
+}
+
+// isHistoryTable returns true if the table is the history table, which is not managed by schema files
+func (r *Alternator) isHistoryTable(dbName string, tableName string) bool {
+	d, t := historyTableOf(r.DbUri)
+	return d == dbName && t == tableName
+}
+
+// WriteHistory creates the history table if not exists, and inserts the history
+func (r *Alternator) WriteHistory(history *History) error {
+	table := historyTableName(r.DbUri)
+	if table == "" {
+		return nil
+	}
+	_, err := r.Db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id bigint NOT NULL AUTO_INCREMENT,
+  schema_hash varchar(64) NOT NULL,
+  status varchar(16) NOT NULL,
+  user varchar(255) NOT NULL,
+  host varchar(255) NOT NULL,
+  started_at datetime NOT NULL,
+  finished_at datetime NOT NULL,
+  statements longtext NOT NULL,
+  PRIMARY KEY (id)
+)`, table))
+	if err != nil {
+		return fmt.Errorf("failed to create history table %s : %w", table, err)
+	}
+	b, err := json.Marshal(history.Statements)
+	if err != nil {
+		return fmt.Errorf("failed to serialize history : %w", err)
+	}
+	ret, err := r.Db.Exec(fmt.Sprintf("INSERT INTO %s (schema_hash, status, user, host, started_at, finished_at, statements) VALUES (?, ?, ?, ?, ?, ?, ?)", table),
+		history.SchemaHash, history.Status, history.User, history.Host, history.StartedAt, history.FinishedAt, string(b))
+	if err != nil {
+		return fmt.Errorf("failed to insert history into %s : %w", table, err)
+	}
+	history.Id, _ = ret.LastInsertId()
+	return nil
+}
+
+// ListHistories returns the latest histories in descending order
+func (r *Alternator) ListHistories(limit int) ([]*History, error) {
+	return r.queryHistories("ORDER BY id DESC LIMIT ?", limit)
+}
+
+// GetHistory returns the history of the ID, or nil if not found
+func (r *Alternator) GetHistory(id int64) (*History, error) {
+	histories, err := r.queryHistories("WHERE id = ?", id)
+	if err != nil || len(histories) == 0 {
+		return nil, err
+	}
+	return histories[0], nil
+}
+
+func (r *Alternator) queryHistories(clause string, args ...any) ([]*History, error) {
+	table := historyTableName(r.DbUri)
+	if table == "" {
+		return nil, fmt.Errorf("history table requires a database name. Use --history-table <database>.<table> or a URL with a database name")
+	}
+	rows, err := r.Db.Query(fmt.Sprintf("SELECT id, schema_hash, status, user, host, started_at, finished_at, statements FROM %s %s", table, clause), args...)
+	if err != nil {
+		// The table is created by the first apply
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			return []*History{}, nil
+		}
+		return nil, fmt.Errorf("failed to query history table %s : %w", table, err)
+	}
+	defer rows.Close()
+
+	histories := []*History{}
+	for rows.Next() {
+		var h History
+		var statements string
+		var startedAt, finishedAt []byte
+		if err := rows.Scan(&h.Id, &h.SchemaHash, &h.Status, &h.User, &h.Host, &startedAt, &finishedAt, &statements); err != nil {
+			return nil, fmt.Errorf("failed to read history table %s : %w", table, err)
+		}
+		h.StartedAt = historyTime(startedAt)
+		h.FinishedAt = historyTime(finishedAt)
+		if err := json.Unmarshal([]byte(statements), &h.Statements); err != nil {
+			return nil, fmt.Errorf("failed to parse statements of history %d : %w", h.Id, err)
+		}
+		histories = append(histories, &h)
+	}
+	return histories, rows.Err()
+}
+
+// historyTime formats DATETIME values, which are returned as time or text depending on parseTime parameter of the driver
+func historyTime(b []byte) string {
+	if t, err := time.Parse("2006-01-02 15:04:05", string(b)); err == nil {
+		return t.Format(historyTimeFormat)
+	}
+	if t, err := time.Parse(time.RFC3339, string(b)); err == nil {
+		return t.UTC().Format(historyTimeFormat)
+	}
+	return string(b)
+}
+
+// recordHistory writes the history, which only warns on failure not to hide the result of the apply
+func recordHistory(alternator *Alternator, history *History) {
+	history.Finish()
+	if err := alternator.WriteHistory(history); err != nil {
+		Yellow.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+}
+
+func printHistorySummary(h *History) {
+	fmt.Printf("%-6d ", h.Id)
+	printHistoryStatus(h.Status, "%-9s")
+	executed := 0
+	for _, s := range h.Statements {
+		if s.Status == HistoryStatusSucceeded || s.Status == HistoryStatusFailed {
+			executed++
+		}
+	}
+	fmt.Printf(" %s - %s UTC  %s@%s  %d of %d statements  %s\n", h.StartedAt, h.FinishedAt, h.User, h.Host,
+		executed, len(h.Statements), shortHash(h.SchemaHash))
+}
+
+func printHistory(h *History) {
+	bPrintf("Apply %d ", h.Id)
+	printHistoryStatus(h.Status, "%s")
+	fmt.Println()
+	fmt.Printf("Schema hash : %s\n", h.SchemaHash)
+	fmt.Printf("Executed by : %s@%s\n", h.User, h.Host)
+	fmt.Printf("Started at  : %s UTC\n", h.StartedAt)
+	fmt.Printf("Finished at : %s UTC\n", h.FinishedAt)
+	fmt.Println()
+	for _, s := range h.Statements {
+		switch s.Status {
+		case HistoryStatusSucceeded:
+			Green.Printf("  ✓ %s\n", s.Statement)
+		case HistoryStatusFailed:
+			Red.Printf("  ✗ %s\n", s.Statement)
+			Red.Printf("    %s\n", s.Error)
+		case HistoryStatusSkipped:
+			fmt.Printf("  - %s (completed before resuming)\n", s.Statement)
+		default:
+			fmt.Printf("    %s\n", s.Statement)
+		}
+	}
+}
+
+func printHistoryStatus(status string, format string) {
+	if status == HistoryStatusSucceeded {
+		Green.Printf(format, status)
+	} else {
+		Red.Printf(format, status)
+	}
+}
+
+func shortHash(s string) string {
+	if len(s) > 12 {
+		return s[:12]
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHistory(t *testing.T) {
+	journal := NewJournal("source", "remote", []string{"s1", "s2", "s3", "s4"})
+	journal.Completed = []*JournalEntry{{Statement: "s1"}}
+
+	history := NewHistory(journal)
+	assert.Equal(t, "source", history.SchemaHash)
+	assert.NotEmpty(t, history.StartedAt)
+
+	history.Succeed(1)
+	history.Finish()
+	assert.Equal(t, HistoryStatusSucceeded, history.Status)
+
+	history.Fail(2, errors.New("error"))
+	history.Finish()
+	assert.Equal(t, HistoryStatusFailed, history.Status)
+	assert.Equal(t, []*HistoryStatement{
+		{Statement: "s1", Status: HistoryStatusSkipped},
+		{Statement: "s2", Status: HistoryStatusSucceeded},
+		{Statement: "s3", Status: HistoryStatusFailed, Error: "error"},
+		{Statement: "s4", Status: HistoryStatusPending},
+	}, history.Statements)
+}
+
+func TestHistoryTableName(t *testing.T) {
+	defer func() { historyTable = DefaultHistoryTable }()
+
+	historyTable = DefaultHistoryTable
+	assert.Equal(t, "`db1`.`alternator_history`", historyTableName(&DatabaseUri{DbName: "db1"}))
+	assert.Equal(t, "", historyTableName(&DatabaseUri{}))
+
+	historyTable = "ops.applies"
+	assert.Equal(t, "`ops`.`applies`", historyTableName(&DatabaseUri{DbName: "db1"}))
+	assert.Equal(t, "`ops`.`applies`", historyTableName(&DatabaseUri{}))
+
+	historyTable = ""
+	assert.Equal(t, "", historyTableName(&DatabaseUri{DbName: "db1"}))
+}
+
+func TestHistory(t *testing.T) {
+	managesAllDatabases = false
+	historyTable = DefaultHistoryTable
+	defer func() { managesAllDatabases = true }()
+
+	for _, db := range Databases {
+		fixture := Fixture{db, filepath.Join(RootPath, "db")}
+		t.Run(fixture.Name(), func(t *testing.T) {
+			url := fmt.Sprintf("%s://root@localhost:%d/db1", fixture.Dialect, fixture.Port)
+			schemaFile := filepath.Join(t.TempDir(), "schema.sql")
+
+			// when
+			dbUri, err := NewDatabaseUri(url)
+			require.NoError(t, err)
+			alternator, err := NewAlternator(dbUri)
+			require.NoError(t, err)
+			defer alternator.Close()
+			_, err = alternator.Db.Exec("DROP DATABASE IF EXISTS db1")
+			require.NoError(t, err)
+			_, err = alternator.Db.Exec("CREATE DATABASE db1")
+			require.NoError(t, err)
+
+			err = os.WriteFile(schemaFile, []byte("CREATE DATABASE db1; USE db1; CREATE TABLE t1 (id int PRIMARY KEY);"), 0644)
+			require.NoError(t, err)
+			alt := ApplyCmd(schemaFile, url, ApplyParam)
+			require.NotNil(t, alt)
+
+			// then
+			histories := HistoryCmd(url, HistoryParams{Limit: 10})
+			require.Len(t, histories, 1)
+			assert.Equal(t, HistoryStatusSucceeded, histories[0].Status)
+			assert.Len(t, histories[0].Statements, len(alt.Statements()))
+			for _, s := range histories[0].Statements {
+				assert.Equal(t, HistoryStatusSucceeded, s.Status)
+			}
+
+			// the history table is not a part of the schema
+			alt = PlanCmd(schemaFile, url, PlanParams{})
+			assert.Nil(t, alt)
+
+			history, err := alternator.GetHistory(histories[0].Id)
+			require.NoError(t, err)
+			assert.Equal(t, histories[0], history)
+		})
+	}
+}
@@ -0,0 +1,596 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+const (
+	// FetchMethodShowCreate builds remote schemas by parsing the output of SHOW CREATE TABLE
+	FetchMethodShowCreate = "show-create"
+	// FetchMethodInformationSchema builds remote schemas from information_schema without parsing table definitions
+	FetchMethodInformationSchema = "information-schema"
+)
+
+var SupportedFetchMethods = hashset.New(FetchMethodShowCreate, FetchMethodInformationSchema)
+
+var currentTimestampRegexp = regexp.MustCompile(`(?i)^(CURRENT_TIMESTAMP|NOW|LOCALTIME|LOCALTIMESTAMP)(\(\d*\))?$`)
+
+// informationSchemaTable is the rows of information_schema of a table
+type informationSchemaTable struct {
+	name        string
+	engine      string
+	collation   string
+	charset     string
+	autoInc     sql.NullInt64
+	comment     string
+	options     string
+	columns     []*informationSchemaColumn
+	indexes     []*informationSchemaIndex
+	foreignKeys []*parser.ForeignKeyDefinition
+	checks      []*parser.CheckConstraintDefinition
+}
+
+type informationSchemaColumn struct {
+	name       string
+	columnType string
+	nullable   string
+	dflt       sql.NullString
+	charset    sql.NullString
+	collation  sql.NullString
+	extra      string
+	comment    string
+	generation sql.NullString
+	srid       sql.NullString
+}
+
+type informationSchemaIndex struct {
+	name      string
+	nonUnique int
+	indexType string
+	comment   string
+	visible   string
+	keyParts  []parser.KeyPart
+}
+
+// buildCreateTables builds CREATE TABLE statements of the tables from information_schema.
+// Each information_schema table is queried once for the database, instead of once for each table.
+// Partitioned tables and tables with spatial indexes, which are not modeled, are fetched by SHOW CREATE TABLE instead.
+func (r *Alternator) buildCreateTables(dbName string, tableNames []string) ([]parser.Statement, error) {
+	ctx := context.Background()
+	conn, err := r.Db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection : %w", err)
+	}
+	defer conn.Close()
+	if r.ServerVersion.AtLeast(8, 0, 0) {
+		// Statistics such as AUTO_INCREMENT are cached for a day by default
+		if _, err := conn.ExecContext(ctx, "SET SESSION information_schema_stats_expiry = 0"); err != nil {
+			return nil, fmt.Errorf("failed to set information_schema_stats_expiry : %w", err)
+		}
+	}
+
+	tables := map[string]*informationSchemaTable{}
+	for _, t := range tableNames {
+		tables[t] = &informationSchemaTable{name: t}
+	}
+	for _, f := range []func(context.Context, *sql.Conn, string, map[string]*informationSchemaTable) error{
+		r.queryInformationSchemaTables,
+		r.queryInformationSchemaColumns,
+		r.queryInformationSchemaStatistics,
+		r.queryInformationSchemaForeignKeys,
+		r.queryInformationSchemaChecks,
+	} {
+		if err := f(ctx, conn, dbName, tables); err != nil {
+			return nil, err
+		}
+	}
+
+	var ret []parser.Statement
+	for _, name := range tableNames {
+		t := tables[name]
+		if !t.modeled() {
+			logrus.Debugf("fetching `%s`.`%s` by SHOW CREATE TABLE", dbName, name)
+			s, err := r.getCreateTable(dbName, name)
+			if err != nil {
+				return nil, err
+			}
+			statements, err := parser.NewParser(strings.NewReader(s)).Parse()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse schema : %w", err)
+			}
+			for _, st := range statements {
+				if cts, ok := st.(parser.CreateTableStatement); ok {
+					cts.DbName = dbName
+					ret = append(ret, cts)
+				}
+			}
+			continue
+		}
+		s, err := t.createTable(dbName, r.GlobalConfig, r.ServerVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build table `%s`.`%s` : %w", dbName, name, err)
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+func (r *Alternator) queryInformationSchemaTables(ctx context.Context, conn *sql.Conn, dbName string, tables map[string]*informationSchemaTable) error {
+	rows, err := conn.QueryContext(ctx, `SELECT t.TABLE_NAME, IFNULL(t.ENGINE, ''), IFNULL(t.TABLE_COLLATION, ''), IFNULL(c.CHARACTER_SET_NAME, ''),
+       t.AUTO_INCREMENT, IFNULL(t.TABLE_COMMENT, ''), IFNULL(t.CREATE_OPTIONS, '')
+FROM information_schema.TABLES t
+LEFT JOIN information_schema.COLLATIONS c ON c.COLLATION_NAME = t.TABLE_COLLATION
+WHERE t.TABLE_SCHEMA = ?`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query information_schema.TABLES : %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var v informationSchemaTable
+		if err := rows.Scan(&name, &v.engine, &v.collation, &v.charset, &v.autoInc, &v.comment, &v.options); err != nil {
+			return fmt.Errorf("failed to read information_schema.TABLES : %w", err)
+		}
+		if t, ok := tables[name]; ok {
+			v.name = name
+			*t = v
+		}
+	}
+	return rows.Err()
+}
+
+func (r *Alternator) queryInformationSchemaColumns(ctx context.Context, conn *sql.Conn, dbName string, tables map[string]*informationSchemaTable) error {
+	// SRID is available since MySQL 8.0
+	srid := "NULL"
+	if r.ServerVersion.AtLeast(8, 0, 0) {
+		srid = "SRS_ID"
+	}
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, CHARACTER_SET_NAME, COLLATION_NAME,
+       EXTRA, COLUMN_COMMENT, GENERATION_EXPRESSION, %s
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME, ORDINAL_POSITION`, srid), dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query information_schema.COLUMNS : %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		var c informationSchemaColumn
+		if err := rows.Scan(&table, &c.name, &c.columnType, &c.nullable, &c.dflt, &c.charset, &c.collation,
+			&c.extra, &c.comment, &c.generation, &c.srid); err != nil {
+			return fmt.Errorf("failed to read information_schema.COLUMNS : %w", err)
+		}
+		if t, ok := tables[table]; ok {
+			t.columns = append(t.columns, &c)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *Alternator) queryInformationSchemaStatistics(ctx context.Context, conn *sql.Conn, dbName string, tables map[string]*informationSchemaTable) error {
+	// Invisible indexes and functional key parts are available since MySQL 8.0
+	visible, expression := "'YES'", "NULL"
+	if r.ServerVersion.AtLeast(8, 0, 0) {
+		visible = "IS_VISIBLE"
+	}
+	if r.ServerVersion.AtLeast(8, 0, 13) {
+		expression = "EXPRESSION"
+	}
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME, SUB_PART, COLLATION, INDEX_TYPE, INDEX_COMMENT, %s, %s
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME, INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX`, visible, expression), dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query information_schema.STATISTICS : %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		var idx informationSchemaIndex
+		var column, subPart, collation, expr sql.NullString
+		if err := rows.Scan(&table, &idx.name, &idx.nonUnique, &column, &subPart, &collation, &idx.indexType, &idx.comment,
+			&idx.visible, &expr); err != nil {
+			return fmt.Errorf("failed to read information_schema.STATISTICS : %w", err)
+		}
+		t, ok := tables[table]
+		if !ok {
+			continue
+		}
+		keyPart := parser.KeyPart{Column: column.String, Length: subPart.String}
+		if collation.String == "D" {
+			keyPart.Order = "DESC"
+		}
+		if expr.Valid {
+			// Functional key parts are enclosed in parentheses
+			keyPart.Expression = formatExpression("(" + expr.String + ")")
+			keyPart.Column = firstIdentifier(keyPart.Expression)
+		}
+		if n := len(t.indexes); n > 0 && t.indexes[n-1].name == idx.name {
+			t.indexes[n-1].keyParts = append(t.indexes[n-1].keyParts, keyPart)
+			continue
+		}
+		idx.keyParts = []parser.KeyPart{keyPart}
+		t.indexes = append(t.indexes, &idx)
+	}
+	return rows.Err()
+}
+
+func (r *Alternator) queryInformationSchemaForeignKeys(ctx context.Context, conn *sql.Conn, dbName string, tables map[string]*informationSchemaTable) error {
+	rows, err := conn.QueryContext(ctx, `SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+       rc.UPDATE_RULE, rc.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+  ON rc.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND rc.TABLE_NAME = k.TABLE_NAME AND rc.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query information_schema.KEY_COLUMN_USAGE : %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, column, refSchema, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&table, &name, &column, &refSchema, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return fmt.Errorf("failed to read information_schema.KEY_COLUMN_USAGE : %w", err)
+		}
+		t, ok := tables[table]
+		if !ok {
+			continue
+		}
+		if n := len(t.foreignKeys); n > 0 && t.foreignKeys[n-1].ConstraintName == name {
+			fk := t.foreignKeys[n-1]
+			fk.KeyPartList = append(fk.KeyPartList, parser.KeyPart{Column: column})
+			fk.ReferenceDefinition.KeyPartList = append(fk.ReferenceDefinition.KeyPartList, parser.KeyPart{Column: refColumn})
+			continue
+		}
+		// The database name is omitted for the tables in the same database, as SHOW CREATE TABLE does
+		if refSchema == dbName {
+			refSchema = ""
+		}
+		t.foreignKeys = append(t.foreignKeys, &parser.ForeignKeyDefinition{
+			ConstraintName: name,
+			KeyPartList:    []parser.KeyPart{{Column: column}},
+			ReferenceDefinition: parser.ReferenceDefinition{
+				DbName:      refSchema,
+				TableName:   refTable,
+				KeyPartList: []parser.KeyPart{{Column: refColumn}},
+				ReferenceOptions: parser.ReferenceOptions{
+					OnDelete: onDelete,
+					OnUpdate: onUpdate,
+				},
+			},
+		})
+	}
+	return rows.Err()
+}
+
+func (r *Alternator) queryInformationSchemaChecks(ctx context.Context, conn *sql.Conn, dbName string, tables map[string]*informationSchemaTable) error {
+	// Check constraints are available since MySQL 8.0.16
+	if !r.ServerVersion.AtLeast(8, 0, 16) {
+		return nil
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT tc.TABLE_NAME, cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE, tc.ENFORCED
+FROM information_schema.CHECK_CONSTRAINTS cc
+JOIN information_schema.TABLE_CONSTRAINTS tc
+  ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME AND tc.CONSTRAINT_TYPE = 'CHECK'
+WHERE cc.CONSTRAINT_SCHEMA = ?
+ORDER BY tc.TABLE_NAME, cc.CONSTRAINT_NAME`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query information_schema.CHECK_CONSTRAINTS : %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, clause, enforced string
+		if err := rows.Scan(&table, &name, &clause, &enforced); err != nil {
+			return fmt.Errorf("failed to read information_schema.CHECK_CONSTRAINTS : %w", err)
+		}
+		t, ok := tables[table]
+		if !ok {
+			continue
+		}
+		check := &parser.CheckConstraintDefinition{
+			ConstraintName: name,
+			Check:          formatExpression(clause),
+		}
+		if enforced == "NO" {
+			check.CheckConstraintOptions.Enforcement = "NOT ENFORCED"
+		}
+		t.checks = append(t.checks, check)
+	}
+	return rows.Err()
+}
+
+// modeled returns false if the table has definitions which cannot be built from information_schema
+func (r *informationSchemaTable) modeled() bool {
+	for _, o := range strings.Fields(r.options) {
+		if strings.EqualFold(o, "partitioned") {
+			return false
+		}
+	}
+	for _, idx := range r.indexes {
+		if idx.indexType == "SPATIAL" {
+			return false
+		}
+	}
+	return true
+}
+
+// createTable builds the CREATE TABLE statement as parsed from the output of SHOW CREATE TABLE
+func (r *informationSchemaTable) createTable(dbName string, config *parser.GlobalConfig, version lib.ServerVersion) (parser.CreateTableStatement, error) {
+	var definitions []interface{}
+	for _, c := range r.columns {
+		column, err := c.columnDefinition(r, config)
+		if err != nil {
+			return parser.CreateTableStatement{}, err
+		}
+		definitions = append(definitions, column)
+	}
+	for _, idx := range r.indexes {
+		options := parser.IndexOptions{Comment: quoteString(idx.comment)}
+		if idx.visible == "NO" {
+			options.Visibility = "INVISIBLE"
+		}
+		switch {
+		case idx.name == "PRIMARY":
+			definitions = append(definitions, &parser.PrimaryKeyDefinition{KeyPartList: idx.keyParts, IndexOptions: options})
+		case idx.nonUnique == 0:
+			definitions = append(definitions, &parser.UniqueKeyDefinition{IndexName: idx.name, KeyPartList: idx.keyParts, IndexOptions: options})
+		case idx.indexType == "FULLTEXT":
+			definitions = append(definitions, &parser.FullTextIndexDefinition{IndexName: idx.name, KeyPartList: idx.keyParts, IndexOptions: options})
+		default:
+			definitions = append(definitions, &parser.IndexDefinition{IndexName: idx.name, KeyPartList: idx.keyParts, IndexOptions: options})
+		}
+	}
+	for _, fk := range r.foreignKeys {
+		definitions = append(definitions, fk)
+	}
+	for _, c := range r.checks {
+		definitions = append(definitions, c)
+	}
+
+	options, err := r.tableOptions(config, version)
+	if err != nil {
+		return parser.CreateTableStatement{}, err
+	}
+	return parser.CreateTableStatement{
+		DbName:            dbName,
+		TableName:         r.name,
+		CreateDefinitions: definitions,
+		TableOptions:      options,
+	}, nil
+}
+
+// tableOptions returns the table options shown by SHOW CREATE TABLE
+func (r *informationSchemaTable) tableOptions(config *parser.GlobalConfig, version lib.ServerVersion) (parser.TableOptions, error) {
+	options := parser.TableOptions{
+		Engine:         r.engine,
+		DefaultCharset: r.charset,
+		Comment:        quoteString(r.comment),
+	}
+	// COLLATE is shown if it is not the default of the character set, and always for utf8mb4_0900_ai_ci since MySQL 8.0
+	if r.collation != config.CharsetToCollation[r.charset] || version.AtLeast(8, 0, 0) && r.collation == "utf8mb4_0900_ai_ci" {
+		options.DefaultCollate = r.collation
+	}
+	if r.autoInc.Valid && r.autoInc.Int64 > 1 {
+		options.AutoIncrement = fmt.Sprint(r.autoInc.Int64)
+	}
+	for _, o := range strings.Fields(r.options) {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(k) {
+		case "avg_row_length":
+			options.AvgRowLength = v
+		case "checksum":
+			options.Checksum = v
+		case "compression":
+			options.Compression = quoteString(strings.Trim(v, `'"`))
+		case "delay_key_write":
+			options.DelayKeyWrite = v
+		case "encryption":
+			options.Encryption = quoteString(strings.Trim(v, `'"`))
+		case "key_block_size":
+			options.KeyBlockSize = v
+		case "max_rows":
+			options.MaxRows = v
+		case "min_rows":
+			options.MinRows = v
+		case "pack_keys":
+			options.PackKeys = v
+		case "row_format":
+			options.RowFormat = strings.ToUpper(v)
+		case "stats_auto_recalc":
+			options.StatsAutoRecalc = v
+		case "stats_persistent":
+			options.StatsPersistent = v
+		case "stats_sample_pages":
+			options.StatsSamplePages = v
+		}
+	}
+	return options, nil
+}
+
+// columnDefinition returns the column definition shown by SHOW CREATE TABLE
+func (r *informationSchemaColumn) columnDefinition(table *informationSchemaTable, config *parser.GlobalConfig) (*parser.ColumnDefinition, error) {
+	dataType, err := newDataType(r.columnType)
+	if err != nil {
+		return nil, fmt.Errorf("column `%s` : %w", r.name, err)
+	}
+
+	// CHARACTER SET is shown if the collation differs from the table's one, and COLLATE is shown if the collation is
+	// not the default of the character set
+	charset, collation := "", ""
+	if r.collation.Valid {
+		if r.collation.String != table.collation {
+			charset = r.charset.String
+		}
+		if r.collation.String != config.CharsetToCollation[r.charset.String] ||
+			r.collation.String == "utf8mb4_0900_ai_ci" && table.collation != r.collation.String {
+			collation = r.collation.String
+		}
+	}
+	switch t := dataType.(type) {
+	case parser.StringType:
+		t.Charset, t.Collation = charset, collation
+		dataType = t
+	case parser.StringListType:
+		t.Charset, t.Collation = charset, collation
+		dataType = t
+	}
+
+	options := parser.ColumnOptions{
+		Comment: quoteString(r.comment),
+		Srid:    r.srid.String,
+	}
+	if r.nullable == "NO" {
+		options.Nullability = "NOT NULL"
+	}
+	extra := strings.ToUpper(r.extra)
+	if strings.Contains(extra, "AUTO_INCREMENT") {
+		options.AutoIncrement = true
+	}
+	if strings.Contains(extra, "INVISIBLE") {
+		options.Visibility = "INVISIBLE"
+	}
+	if i := strings.Index(extra, "ON UPDATE "); i >= 0 {
+		options.OnUpdate = strings.Fields(r.extra[i+len("ON UPDATE "):])[0]
+		// Without precision as parsed from SHOW CREATE TABLE
+		if strings.EqualFold(options.OnUpdate, "CURRENT_TIMESTAMP") || strings.EqualFold(options.OnUpdate, "CURRENT_TIMESTAMP()") {
+			options.OnUpdate = "CURRENT_TIMESTAMP"
+		}
+	}
+	if r.generation.String != "" {
+		options.GeneratedAs = "(" + formatExpression(r.generation.String) + ")"
+		if strings.Contains(extra, "STORED GENERATED") {
+			options.GeneratedColumnType = "STORED"
+		} else {
+			options.GeneratedColumnType = "VIRTUAL"
+		}
+	}
+	if r.dflt.Valid {
+		switch {
+		case currentTimestampRegexp.MatchString(r.dflt.String):
+			options.Default = formatExpression(r.dflt.String)
+		case strings.Contains(extra, "DEFAULT_GENERATED"):
+			options.Default = "(" + formatExpression(r.dflt.String) + ")"
+		case strings.HasPrefix(r.dflt.String, "b'") && strings.HasPrefix(r.columnType, "bit"):
+			options.Default = formatExpression(r.dflt.String)
+		default:
+			options.Default = quoteString(r.dflt.String)
+			if options.Default == "" {
+				options.Default = "''"
+			}
+		}
+	}
+
+	return &parser.ColumnDefinition{
+		ColumnName:    r.name,
+		DataType:      dataType,
+		ColumnOptions: options,
+	}, nil
+}
+
+// newDataType returns the data type of COLUMN_TYPE, such as "int unsigned", "decimal(10,2)" or "enum('a','b')"
+func newDataType(columnType string) (interface{}, error) {
+	name, rest, _ := strings.Cut(columnType, " ")
+	args := ""
+	if i := strings.Index(name, "("); i >= 0 {
+		// Arguments of enum and set may contain spaces
+		j := strings.LastIndex(columnType, ")")
+		if j < i {
+			return nil, fmt.Errorf("invalid data type: %s", columnType)
+		}
+		args = columnType[i+1 : j]
+		rest = strings.TrimSpace(columnType[j+1:])
+		name = name[:i]
+	}
+	name = strings.ToLower(name)
+	unsigned := strings.Contains(rest, "unsigned")
+	zerofill := strings.Contains(rest, "zerofill")
+	fieldLen, fieldScale, _ := strings.Cut(args, ",")
+
+	switch name {
+	case "bit", "tinyint", "smallint", "mediumint", "int", "bigint":
+		return parser.IntegerType{Name: name, FieldLen: args, Unsigned: unsigned, Zerofill: zerofill}, nil
+	case "decimal":
+		return parser.FixedPointType{Name: name, FieldLen: fieldLen, FieldScale: fieldScale, Unsigned: unsigned, Zerofill: zerofill}, nil
+	case "float", "double":
+		if fieldScale == "" {
+			fieldLen = ""
+		}
+		return parser.FloatingPointType{Name: name, FieldLen: fieldLen, FieldScale: fieldScale, Unsigned: unsigned, Zerofill: zerofill}, nil
+	case "date", "time", "datetime", "timestamp", "year":
+		return parser.DateAndTimeType{Name: name, FieldLen: args}, nil
+	case "char", "varchar", "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob",
+		"tinytext", "text", "mediumtext", "longtext":
+		return parser.StringType{Name: name, FieldLen: args}, nil
+	case "enum", "set":
+		return parser.StringListType{Name: name, Values: splitQuoted(args)}, nil
+	case "json":
+		return parser.JsonType{Name: name}, nil
+	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return parser.SpatialType{Name: name}, nil
+	case "geomcollection":
+		return parser.SpatialType{Name: "geometrycollection"}, nil
+	}
+	return nil, fmt.Errorf("unsupported data type: %s", columnType)
+}
+
+// splitQuoted splits comma separated quoted strings such as "'a','b,c','d”e'" keeping the quotes
+func splitQuoted(s string) []string {
+	var ret []string
+	inQuote := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			inQuote = !inQuote
+		case s[i] == ',' && !inQuote:
+			ret = append(ret, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(ret, s[start:])
+}
+
+// quoteString returns a string literal escaped as SHOW CREATE TABLE does, or empty string if the value is empty
+func quoteString(s string) string {
+	if s == "" {
+		return ""
+	}
+	r := strings.NewReplacer(`\`, `\\`, "'", "''", "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// formatExpression formats the expression as the parser does, so that it can be compared with the ones parsed from
+// SHOW CREATE TABLE. The expression is returned as it is if the parser cannot handle it.
+func formatExpression(expr string) string {
+	statements, err := parser.NewParser(strings.NewReader(fmt.Sprintf("CREATE TABLE t (CHECK (%s))", expr))).Parse()
+	if err != nil || len(statements) != 1 {
+		return expr
+	}
+	s, ok := statements[0].(parser.CreateTableStatement)
+	if !ok || len(s.GetCheckConstraints()) != 1 {
+		return expr
+	}
+	return s.GetCheckConstraints()[0].Check
+}
+
+// firstIdentifier returns the first quoted identifier in the expression, which is the column of functional key parts
+func firstIdentifier(expr string) string {
+	_, rest, ok := strings.Cut(expr, "`")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, "`")
+	return name
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNewDataType(t *testing.T) {
+	for _, c := range []struct {
+		columnType string
+		expected   interface{}
+	}{
+		{"int", parser.IntegerType{Name: "int"}},
+		{"int(11) unsigned zerofill", parser.IntegerType{Name: "int", FieldLen: "11", Unsigned: true, Zerofill: true}},
+		{"bit(8)", parser.IntegerType{Name: "bit", FieldLen: "8"}},
+		{"decimal(10,2)", parser.FixedPointType{Name: "decimal", FieldLen: "10", FieldScale: "2"}},
+		{"double", parser.FloatingPointType{Name: "double"}},
+		{"float(7,3)", parser.FloatingPointType{Name: "float", FieldLen: "7", FieldScale: "3"}},
+		{"datetime(3)", parser.DateAndTimeType{Name: "datetime", FieldLen: "3"}},
+		{"varchar(255)", parser.StringType{Name: "varchar", FieldLen: "255"}},
+		{"longblob", parser.StringType{Name: "longblob"}},
+		{"enum('a','b c','d,''e')", parser.StringListType{Name: "enum", Values: []string{"'a'", "'b c'", "'d,''e'"}}},
+		{"json", parser.JsonType{Name: "json"}},
+		{"geomcollection", parser.SpatialType{Name: "geometrycollection"}},
+	} {
+		actual, err := newDataType(c.columnType)
+		require.NoError(t, err, c.columnType)
+		assert.Equal(t, c.expected, actual, c.columnType)
+	}
+
+	_, err := newDataType("unknown")
+	assert.Error(t, err)
+}
+
+func TestQuoteString(t *testing.T) {
+	assert.Equal(t, "", quoteString(""))
+	assert.Equal(t, "'abc'", quoteString("abc"))
+	assert.Equal(t, `'it''s a\\b\n'`, quoteString("it's a\\b\n"))
+}
+
+func TestFormatExpression(t *testing.T) {
+	// formatted as parsed from SHOW CREATE TABLE
+	expected, err := parser.NewParser(strings.NewReader("CREATE TABLE t (a int, CHECK ((`a` > 0)))")).Parse()
+	require.NoError(t, err)
+	assert.Equal(t, expected[0].(parser.CreateTableStatement).GetCheckConstraints()[0].Check, formatExpression("(`a` > 0)"))
+
+	// returned as it is if it cannot be parsed
+	assert.Equal(t, "(", formatExpression("("))
+	assert.Equal(t, "a", firstIdentifier("(lower(`a`))"))
+}
+
+func TestInformationSchema(t *testing.T) {
+	defer func() { fetchMethod = FetchMethodShowCreate }()
+
+	for _, fixture := range PlanTestFixtures {
+		t.Run(fixture.Name(), func(t *testing.T) {
+			if lib.Contains(Skipped, fixture.Name()) {
+				t.Skip()
+			}
+			url := fmt.Sprintf("%s://root@localhost:%d/", fixture.Dialect, fixture.Port)
+			err := prepareDb(fixture.Dir, fixture.Database)
+			require.NoError(t, err)
+
+			fetch := func(method string) []*lib.Schema {
+				fetchMethod = method
+				dbUri, err := NewDatabaseUri(url)
+				require.NoError(t, err)
+				alternator, err := NewAlternator(dbUri)
+				require.NoError(t, err)
+				defer alternator.Close()
+				schemas, err := alternator.FetchSchemas()
+				require.NoError(t, err)
+				return schemas
+			}
+
+			// when
+			expected := fetch(FetchMethodShowCreate)
+			actual := fetch(FetchMethodInformationSchema)
+
+			// then
+			assert.Empty(t, lib.NewDatabaseAlterations(expected, actual).Statements())
+			assert.Equal(t, lib.Fingerprint(expected), lib.Fingerprint(actual))
+		})
+	}
+}
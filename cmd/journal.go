@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kota65535/alternator/lib"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Journal records statements executed by apply command, so that the command can be resumed after a failure.
+type Journal struct {
+	// Fingerprint of the plan, which identifies the journal
+	Fingerprint string `json:"fingerprint"`
+	// Fingerprint of the schema file or the plan file being applied
+	Source string `json:"source"`
+	// Fingerprint of the remote schemas before executing any statements
+	RemoteFingerprint string          `json:"remote_fingerprint"`
+	Statements        []string        `json:"statements"`
+	Completed         []*JournalEntry `json:"completed"`
+	// Statement being executed, which is recorded before executing it and cleared after it is completed
+	Executing string `json:"executing,omitempty"`
+	// Online DDL algorithms of ALTER TABLE statements, keyed by the statements
+	OnlineDdls map[string]lib.OnlineDdl `json:"online_ddls,omitempty"`
+	// Kinds of destruction of destructive statements, keyed by the statements
+	Destructions map[string]lib.Destruction `json:"destructions,omitempty"`
+}
+
+type JournalEntry struct {
+	Statement string `json:"statement"`
+	// Fingerprint of the remote schemas after executing the statement
+	RemoteFingerprint string `json:"remote_fingerprint"`
+}
+
+func NewJournal(source string, remoteFingerprint string, statements []string) *Journal {
+	return &Journal{
+		Fingerprint:       planFingerprint(remoteFingerprint, statements),
+		Source:            source,
+		RemoteFingerprint: remoteFingerprint,
+		Statements:        statements,
+		Completed:         []*JournalEntry{},
+	}
+}
+
+// planFingerprint returns a hash of the statements and the remote schemas which they are computed against
+func planFingerprint(remoteFingerprint string, statements []string) string {
+	sum := sha256.Sum256([]byte(remoteFingerprint + "\n" + strings.Join(statements, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResumeIndex returns the index of the statement to resume from.
+// It verifies that the completed statements actually took effect by comparing the current remote schema fingerprint
+// with the ones recorded after executing each statement.
+// If the command stopped after executing a statement but before recording it, the remote schema has changed from the
+// last completed one. In that case the statement is recorded as completed with the current fingerprint.
+func (r *Journal) ResumeIndex(remoteFingerprint string) (int, error) {
+	for i := len(r.Completed) - 1; i >= 0; i-- {
+		if r.Completed[i].RemoteFingerprint == remoteFingerprint {
+			return i + 1, nil
+		}
+	}
+	if r.RemoteFingerprint == remoteFingerprint {
+		return 0, nil
+	}
+	if n := len(r.Completed); r.Executing != "" && n < len(r.Statements) && r.Statements[n] == r.Executing {
+		r.Completed = append(r.Completed, &JournalEntry{
+			Statement:         r.Executing,
+			RemoteFingerprint: remoteFingerprint,
+		})
+		r.Executing = ""
+		return n + 1, nil
+	}
+	return 0, fmt.Errorf("the remote database schema does not match the journal. It may have been changed by others")
+}
+
+func journalPath(dir string, fingerprint string) string {
+	return filepath.Join(dir, fingerprint+".json")
+}
+
+func writeJournal(dir string, journal *Journal) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create journal directory: %s : %w", dir, err)
+	}
+	b, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize journal : %w", err)
+	}
+	path := journalPath(dir, journal.Fingerprint)
+	err = os.WriteFile(path, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %s : %w", path, err)
+	}
+	return nil
+}
+
+func removeJournal(dir string, journal *Journal) error {
+	path := journalPath(dir, journal.Fingerprint)
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal: %s : %w", path, err)
+	}
+	return nil
+}
+
+// findJournal returns the journal of the source left by an unfinished apply command.
+// It returns nil without error if no journal is found.
+func findJournal(dir string, source string) (*Journal, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journals in %s : %w", dir, err)
+	}
+	var found *Journal
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal: %s : %w", p, err)
+		}
+		var journal Journal
+		if err := json.Unmarshal(b, &journal); err != nil {
+			return nil, fmt.Errorf("failed to parse journal: %s : %w", p, err)
+		}
+		if journal.Source != source {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("found multiple journals of the same schema in %s. Remove unnecessary ones", dir)
+		}
+		found = &journal
+	}
+	return found, nil
+}
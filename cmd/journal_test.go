@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal := NewJournal("source1", "fp0", []string{"s1", "s2", "s3"})
+	journal.Completed = append(journal.Completed, &JournalEntry{Statement: "s1", RemoteFingerprint: "fp1"})
+	err := writeJournal(dir, journal)
+	require.NoError(t, err)
+	err = writeJournal(dir, NewJournal("source2", "fp0", []string{"s4"}))
+	require.NoError(t, err)
+
+	actual, err := findJournal(dir, "source1")
+	require.NoError(t, err)
+	assert.Equal(t, journal, actual)
+
+	err = removeJournal(dir, journal)
+	require.NoError(t, err)
+	actual, err = findJournal(dir, "source1")
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestJournalResumeIndex(t *testing.T) {
+	journal := NewJournal("source", "fp0", []string{"s1", "s2", "s3"})
+	journal.Completed = []*JournalEntry{
+		{Statement: "s1", RemoteFingerprint: "fp1"},
+		{Statement: "s2", RemoteFingerprint: "fp2"},
+	}
+
+	i, err := journal.ResumeIndex("fp2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+
+	// the last statement did not take effect
+	i, err = journal.ResumeIndex("fp1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	i, err = journal.ResumeIndex("fp0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	_, err = journal.ResumeIndex("unknown")
+	assert.ErrorContains(t, err, "does not match the journal")
+}
+
+func TestJournalResumeIndexExecuting(t *testing.T) {
+	journal := NewJournal("source", "fp0", []string{"s1", "s2", "s3"})
+	journal.Completed = []*JournalEntry{
+		{Statement: "s1", RemoteFingerprint: "fp1"},
+	}
+	journal.Executing = "s2"
+
+	// the executing statement did not take effect
+	i, err := journal.ResumeIndex("fp1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	// the executing statement took effect, but was not recorded as completed
+	i, err = journal.ResumeIndex("fp2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+	assert.Equal(t, []*JournalEntry{
+		{Statement: "s1", RemoteFingerprint: "fp1"},
+		{Statement: "s2", RemoteFingerprint: "fp2"},
+	}, journal.Completed)
+	assert.Empty(t, journal.Executing)
+}
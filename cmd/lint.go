@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed lint.tmpl
+var lintUsage string
+
+const FormatSarif = "sarif"
+
+type LintParams struct {
+	Format     string
+	Severities map[string]string
+}
+
+func init() {
+	var params LintParams
+
+	c := &cobra.Command{
+		Use:   "lint <schema-file>",
+		Short: "Check the local schema file by lint rules.",
+		Long:  "Check the local schema file by lint rules.",
+		Args:  envArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			args, err := argsWithEnv(args, ArgSchema)
+			cobra.CheckErr(err)
+			if params.Format != FormatText && params.Format != FormatJson && params.Format != FormatSarif {
+				cobra.CheckErr(fmt.Errorf("unsupported format: %s", params.Format))
+			}
+			results := LintCmd(args[0], params)
+			cobra.CheckErr(checkLintResults(results))
+		},
+	}
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text, json or sarif)")
+	c.Flags().StringToStringVar(&params.Severities, "severity", nil, "Severities of rules (e.g. column-comment=off,no-timestamp=error)")
+	rootCmd.AddCommand(c)
+	c.SetUsageTemplate(lintUsage)
+}
+
+func LintCmd(path string, params LintParams) []*lib.LintResult {
+	severities := map[string]lib.Severity{}
+	for k, v := range params.Severities {
+		s, err := lib.ParseSeverity(v)
+		cobra.CheckErr(err)
+		severities[k] = s
+	}
+
+	sources, err := readSchemaFiles(path)
+	cobra.CheckErr(err)
+	_, err = lib.NewSchemasFromSources(sources, &parser.GlobalConfig{}, hashset.New())
+	cobra.CheckErr(err)
+
+	results, err := lib.Lint(sources, severities)
+	cobra.CheckErr(err)
+
+	switch params.Format {
+	case FormatJson:
+		printJson(results)
+	case FormatSarif:
+		printJson(newSarifLog(results, severities))
+	default:
+		printLintResults(results)
+	}
+	return results
+}
+
+func printLintResults(results []*lib.LintResult) {
+	if len(results) == 0 {
+		bPrintln("No problems found.")
+		return
+	}
+	numErrors := 0
+	for _, r := range results {
+		fmt.Printf("%s:%d: ", r.File, r.Line)
+		if r.Severity == lib.SeverityError {
+			Red.Printf("%-7s", r.Severity)
+			numErrors++
+		} else {
+			Yellow.Printf("%-7s", r.Severity)
+		}
+		fmt.Printf(" %s (%s)\n", r.Message, r.Rule)
+	}
+	ePrintln()
+	bPrintf("%d problems (%d errors, %d warnings)\n", len(results), numErrors, len(results)-numErrors)
+}
+
+// checkLintResults returns an error if any problem of error severity is found
+func checkLintResults(results []*lib.LintResult) error {
+	numErrors := 0
+	for _, r := range results {
+		if r.Severity == lib.SeverityError {
+			numErrors++
+		}
+	}
+	if numErrors > 0 {
+		return fmt.Errorf("found %d lint errors", numErrors)
+	}
+	return nil
+}
+
+// sarifLog is a lint report in SARIF 2.1.0 format, which code scanning services such as GitHub accept
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func sarifLevel(s lib.Severity) string {
+	switch s {
+	case lib.SeverityError:
+		return "error"
+	case lib.SeverityWarning:
+		return "warning"
+	}
+	return "none"
+}
+
+func newSarifLog(results []*lib.LintResult, severities map[string]lib.Severity) *sarifLog {
+	rules := []sarifRule{}
+	for _, r := range lib.LintRules() {
+		severity := r.Severity
+		if s, ok := severities[r.Name]; ok {
+			severity = s
+		}
+		rules = append(rules, sarifRule{
+			Id:                   r.Name,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifRuleConfiguration{Level: sarifLevel(severity)},
+		})
+	}
+	sarifResults := []sarifResult{}
+	for _, r := range results {
+		sarifResults = append(sarifResults, sarifResult{
+			RuleId:  r.Rule,
+			Level:   sarifLevel(r.Severity),
+			Message: sarifMessage{Text: r.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: filepath.ToSlash(strings.TrimPrefix(r.File, "./"))},
+					Region:           sarifRegion{StartLine: r.Line},
+				},
+			}},
+		})
+	}
+	return &sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "alternator",
+				Version:        version,
+				InformationUri: "https://github.com/kota65535/alternator",
+				Rules:          rules,
+			}},
+			Results: sarifResults,
+		}},
+	}
+}
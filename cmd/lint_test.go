@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/kota65535/alternator/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	path := filepath.Join(RootPath, "_lint", "schema.sql")
+	results := LintCmd(path, LintParams{Format: FormatText})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "no-timestamp", results[0].Rule)
+	assert.Equal(t, 6, results[0].Line)
+	assert.Equal(t, "primary-key", results[1].Rule)
+	assert.Equal(t, 8, results[1].Line)
+	assert.EqualError(t, checkLintResults(results), "found 1 lint errors")
+
+	results = LintCmd(path, LintParams{Format: FormatText, Severities: map[string]string{"primary-key": "warning"}})
+	assert.NoError(t, checkLintResults(results))
+}
+
+func TestSarifLog(t *testing.T) {
+	results := []*lib.LintResult{
+		{Rule: "primary-key", Severity: lib.SeverityError, Message: "table `t2` has no primary key", File: "./schema/db1.sql", Line: 8, Database: "db1", Table: "t2"},
+	}
+	log := newSarifLog(results, map[string]lib.Severity{"column-comment": lib.SeverityOff})
+
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, len(lib.LintRules()), len(run.Tool.Driver.Rules))
+	for _, r := range run.Tool.Driver.Rules {
+		if r.Id == "column-comment" {
+			assert.Equal(t, "none", r.DefaultConfiguration.Level)
+		}
+	}
+	assert.Equal(t, []sarifResult{{
+		RuleId:  "primary-key",
+		Level:   "error",
+		Message: sarifMessage{Text: "table `t2` has no primary key"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: "schema/db1.sql"},
+				Region:           sarifRegion{StartLine: 8},
+			},
+		}},
+	}}, run.Results)
+}
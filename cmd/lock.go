@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultLockPrefix is the prefix of the advisory lock name taken by apply command
+const DefaultLockPrefix = "alternator"
+
+// maxLockNameLength is the maximum length of lock names accepted by GET_LOCK since MySQL 5.7
+const maxLockNameLength = 64
+
+// Lock is a MySQL advisory lock taken by GET_LOCK.
+// The lock is held by a dedicated connection because it is scoped to the session, and it is released by MySQL when the
+// connection is closed, including when the process exits without releasing it.
+type Lock struct {
+	Name string
+	conn *sql.Conn
+}
+
+// defaultLockName returns "alternator:<database>", or "alternator" if the URI has no database name.
+// If the name is too long, the database name is shortened and followed by its hash, so that it stays unique.
+func defaultLockName(dbUri *DatabaseUri) string {
+	name := DefaultLockPrefix
+	if dbUri.DbName != "" {
+		name += ":" + dbUri.DbName
+	}
+	if len(name) > maxLockNameLength {
+		sum := sha256.Sum256([]byte(dbUri.DbName))
+		hash := hex.EncodeToString(sum[:8])
+		name = name[:maxLockNameLength-len(hash)-1] + ":" + hash
+	}
+	return name
+}
+
+// Lock takes the advisory lock of the name.
+// It fails immediately if another session holds the lock and the timeout is zero, waits up to the timeout if positive,
+// and waits indefinitely if negative.
+func (r *Alternator) Lock(name string, timeout time.Duration) (*Lock, error) {
+	ctx := context.Background()
+	conn, err := r.Db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection for lock : %w", err)
+	}
+
+	acquired, err := getLock(ctx, conn, name, 0)
+	if err == nil && !acquired && timeout != 0 {
+		if timeout > 0 {
+			ePrintf("Waiting up to %s for the lock %s held by another session...\n", timeout, name)
+		} else {
+			ePrintf("Waiting for the lock %s held by another session...\n", name)
+		}
+		seconds := -1
+		if timeout > 0 {
+			seconds = int(math.Ceil(timeout.Seconds()))
+		}
+		acquired, err = getLock(ctx, conn, name, seconds)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to get lock %s : %w", name, err)
+	}
+	if !acquired {
+		var holder sql.NullInt64
+		_ = conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?)", name).Scan(&holder)
+		_ = conn.Close()
+		if holder.Valid {
+			return nil, fmt.Errorf("lock %s is held by another session (connection id %d). Another apply may be running", name, holder.Int64)
+		}
+		return nil, fmt.Errorf("lock %s is held by another session. Another apply may be running", name)
+	}
+	return &Lock{Name: name, conn: conn}, nil
+}
+
+// Release releases the lock and closes its connection
+func (r *Lock) Release() error {
+	_, err := r.conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", r.Name)
+	_ = r.conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s : %w", r.Name, err)
+	}
+	return nil
+}
+
+// getLock returns true if the lock is acquired, or false if timed out
+func getLock(ctx context.Context, conn *sql.Conn, name string, seconds int) (bool, error) {
+	var ret sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&ret)
+	if err != nil {
+		return false, err
+	}
+	if !ret.Valid {
+		return false, fmt.Errorf("GET_LOCK returned NULL")
+	}
+	return ret.Int64 == 1, nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultLockName(t *testing.T) {
+	assert.Equal(t, "alternator:db1", defaultLockName(&DatabaseUri{DbName: "db1"}))
+	assert.Equal(t, "alternator", defaultLockName(&DatabaseUri{}))
+	long1 := defaultLockName(&DatabaseUri{DbName: strings.Repeat("d", 64)})
+	long2 := defaultLockName(&DatabaseUri{DbName: strings.Repeat("d", 63) + "e"})
+	assert.Len(t, long1, 64)
+	assert.True(t, strings.HasPrefix(long1, "alternator:"+strings.Repeat("d", 36)+":"))
+	// Database names sharing the prefix get different locks
+	assert.NotEqual(t, long1, long2)
+}
+
+func TestLock(t *testing.T) {
+	for _, db := range Databases {
+		t.Run(fmt.Sprintf("%s_%s", db.Dialect, db.Version), func(t *testing.T) {
+			dbUri, err := NewDatabaseUri(fmt.Sprintf("%s://root@localhost:%d/db1", db.Dialect, db.Port))
+			require.NoError(t, err)
+			alt1, err := NewAlternator(dbUri)
+			require.NoError(t, err)
+			defer alt1.Close()
+			alt2, err := NewAlternator(dbUri)
+			require.NoError(t, err)
+			defer alt2.Close()
+
+			lock, err := alt1.Lock("alternator:test", 0)
+			require.NoError(t, err)
+
+			// fails immediately, or after the timeout
+			_, err = alt2.Lock("alternator:test", 0)
+			assert.ErrorContains(t, err, "lock alternator:test is held by another session")
+			_, err = alt2.Lock("alternator:test", time.Second)
+			assert.ErrorContains(t, err, "lock alternator:test is held by another session")
+
+			// the lock of another name can be taken
+			lock2, err := alt2.Lock("alternator:other", 0)
+			require.NoError(t, err)
+			require.NoError(t, lock2.Release())
+
+			// waits until released
+			go func() {
+				time.Sleep(500 * time.Millisecond)
+				_ = lock.Release()
+			}()
+			lock, err = alt2.Lock("alternator:test", 10*time.Second)
+			require.NoError(t, err)
+			require.NoError(t, lock.Release())
+		})
+	}
+}
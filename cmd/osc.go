@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+const (
+	OscToolGhost     = "gh-ost"
+	OscToolPtOsc     = "pt-osc"
+	DefaultMySQLPort = "3306"
+)
+
+var SupportedOscTools = hashset.New(OscToolGhost, OscToolPtOsc)
+
+// OnlineSchemaChange is a strategy executing ALTER TABLE statements of large tables by online schema change tools
+type OnlineSchemaChange struct {
+	// Tool name, which is either gh-ost or pt-osc
+	Tool string
+	// Path of the tool executable. The default executable name of the tool is used if empty
+	Path string
+	// Tables with rows more than or equal to this are altered by the tool. Ignored if zero
+	MinRows int64
+	// Tables with data and index size in bytes more than or equal to this are altered by the tool. Ignored if zero
+	MinBytes int64
+	// Additional arguments passed to the tool
+	Args []string
+}
+
+var alterTableRegexp = regexp.MustCompile("(?s)^ALTER TABLE `([^`]+)`\\.`([^`]+)` (.+);$")
+
+// parseAlterTable returns the database name, the table name and the clauses of ALTER TABLE statement.
+// Statements renaming tables are not supported, since online schema change tools cannot do it.
+func parseAlterTable(statement string) (string, string, string, bool) {
+	m := alterTableRegexp.FindStringSubmatch(statement)
+	if m == nil || strings.HasPrefix(m[3], "RENAME TO ") {
+		return "", "", "", false
+	}
+	return m[1], m[2], m[3], true
+}
+
+// Command returns the command line to execute the statement by the tool.
+// It returns nil if the statement should be executed directly.
+func (r *OnlineSchemaChange) Command(alternator *Alternator, statement string) ([]string, error) {
+	dbName, tableName, clauses, ok := parseAlterTable(statement)
+	if !ok {
+		return nil, nil
+	}
+	rows, bytes, err := alternator.getTableSize(dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !(r.MinRows > 0 && rows >= r.MinRows || r.MinBytes > 0 && bytes >= r.MinBytes) {
+		return nil, nil
+	}
+	return r.command(alternator.DbUri, dbName, tableName, clauses), nil
+}
+
+// command returns the command line of the tool without the password, which is passed by an option file on run
+func (r *OnlineSchemaChange) command(dbUri *DatabaseUri, dbName string, tableName string, clauses string) []string {
+	host, port, err := net.SplitHostPort(dbUri.Host)
+	if err != nil {
+		host = dbUri.Host
+		port = DefaultMySQLPort
+	}
+	if host == "" {
+		host = "localhost"
+	}
+
+	var ret []string
+	switch r.Tool {
+	case OscToolGhost:
+		ret = []string{
+			r.executable(),
+			fmt.Sprintf("--host=%s", host),
+			fmt.Sprintf("--port=%s", port),
+			fmt.Sprintf("--user=%s", dbUri.User),
+			fmt.Sprintf("--database=%s", dbName),
+			fmt.Sprintf("--table=%s", tableName),
+			fmt.Sprintf("--alter=%s", clauses),
+		}
+		if dbUri.Socket != "" {
+			ret = append(ret, fmt.Sprintf("--socket=%s", dbUri.Socket))
+		}
+	case OscToolPtOsc:
+		dsn := fmt.Sprintf("h=%s,P=%s,u=%s,D=%s,t=%s", host, port, dbUri.User, dbName, tableName)
+		if dbUri.Socket != "" {
+			dsn += fmt.Sprintf(",S=%s", dbUri.Socket)
+		}
+		ret = []string{
+			r.executable(),
+			fmt.Sprintf("--alter=%s", clauses),
+		}
+		ret = append(ret, dsn)
+	}
+	ret = append(ret, r.Args...)
+	return append(ret, "--execute")
+}
+
+func (r *OnlineSchemaChange) executable() string {
+	if r.Path != "" {
+		return r.Path
+	}
+	if r.Tool == OscToolPtOsc {
+		return "pt-online-schema-change"
+	}
+	return r.Tool
+}
+
+// run executes the command line of the tool.
+// The password is written to a temporary option file readable only by the user, so that it is not seen in the
+// process list.
+func (r *OnlineSchemaChange) run(command []string, password string) error {
+	args := command[1:]
+	if password != "" {
+		file, err := writeOptionFile(password)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file)
+		// pt-osc requires the option file to be the first option
+		option := fmt.Sprintf("--defaults-file=%s", file)
+		if r.Tool == OscToolGhost {
+			option = fmt.Sprintf("--conf=%s", file)
+		}
+		args = append([]string{option}, args...)
+	}
+	c := exec.Command(command[0], args...)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	err := c.Run()
+	if err != nil {
+		return fmt.Errorf("failed to execute %s : %w", r.Tool, err)
+	}
+	return nil
+}
+
+// writeOptionFile writes the password to a temporary file in the option file format of MySQL, which gh-ost reads as
+// well. The file is created with permission 0600.
+func writeOptionFile(password string) (string, error) {
+	f, err := os.CreateTemp("", "alternator-osc-*.cnf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create option file : %w", err)
+	}
+	defer f.Close()
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(password)
+	_, err = fmt.Fprintf(f, "[client]\npassword=\"%s\"\n", quoted)
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write option file : %w", err)
+	}
+	return f.Name(), nil
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// commandString returns the command line to show, with the arguments quoted for shells
+func commandString(command []string) string {
+	strs := []string{}
+	for _, c := range command {
+		if !shellSafeRegexp.MatchString(c) {
+			c = "'" + strings.ReplaceAll(c, "'", `'\''`) + "'"
+		}
+		strs = append(strs, c)
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseAlterTable(t *testing.T) {
+	dbName, tableName, clauses, ok := parseAlterTable("ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int AFTER `id`;")
+	require.True(t, ok)
+	assert.Equal(t, "db1", dbName)
+	assert.Equal(t, "t1", tableName)
+	assert.Equal(t, "ADD COLUMN `c1` int AFTER `id`", clauses)
+
+	// Merged clauses are executed together
+	_, _, clauses, ok = parseAlterTable("ALTER TABLE `db1`.`t1` ADD COLUMN `c1` int AFTER `id`, ADD INDEX `idx1` (`c1`);")
+	require.True(t, ok)
+	assert.Equal(t, "ADD COLUMN `c1` int AFTER `id`, ADD INDEX `idx1` (`c1`)", clauses)
+
+	_, _, _, ok = parseAlterTable("ALTER TABLE `db1`.`t1` RENAME TO `db1`.`t2`;")
+	assert.False(t, ok)
+	_, _, _, ok = parseAlterTable("DROP TABLE `db1`.`t1`;")
+	assert.False(t, ok)
+}
+
+func TestOnlineSchemaChangeCommand(t *testing.T) {
+	dbUri := &DatabaseUri{Dialect: "mysql", Host: "db.example.com:13306", User: "bob", Password: "secret"}
+
+	osc := &OnlineSchemaChange{Tool: OscToolGhost, Args: []string{"--allow-on-master"}}
+	assert.Equal(t, []string{
+		"gh-ost",
+		"--host=db.example.com",
+		"--port=13306",
+		"--user=bob",
+		"--database=db1",
+		"--table=t1",
+		"--alter=ADD COLUMN `c1` int",
+		"--allow-on-master",
+		"--execute",
+	}, osc.command(dbUri, "db1", "t1", "ADD COLUMN `c1` int"))
+
+	osc = &OnlineSchemaChange{Tool: OscToolPtOsc}
+	command := osc.command(dbUri, "db1", "t1", "ADD COLUMN `c1` int")
+	assert.Equal(t, []string{
+		"pt-online-schema-change",
+		"--alter=ADD COLUMN `c1` int",
+		"h=db.example.com,P=13306,u=bob,D=db1,t=t1",
+		"--execute",
+	}, command)
+	assert.Equal(t, "pt-online-schema-change '--alter=ADD COLUMN `c1` int' h=db.example.com,P=13306,u=bob,D=db1,t=t1 --execute",
+		commandString(command))
+
+	// unix sockets are passed to both tools
+	dbUri = &DatabaseUri{Dialect: "mysql", User: "bob", Socket: "/var/run/mysqld/mysqld.sock"}
+	osc = &OnlineSchemaChange{Tool: OscToolGhost}
+	assert.Equal(t, []string{
+		"gh-ost",
+		"--host=localhost",
+		"--port=3306",
+		"--user=bob",
+		"--database=db1",
+		"--table=t1",
+		"--alter=ADD COLUMN `c1` int",
+		"--socket=/var/run/mysqld/mysqld.sock",
+		"--execute",
+	}, osc.command(dbUri, "db1", "t1", "ADD COLUMN `c1` int"))
+	osc = &OnlineSchemaChange{Tool: OscToolPtOsc}
+	assert.Equal(t, []string{
+		"pt-online-schema-change",
+		"--alter=ADD COLUMN `c1` int",
+		"h=localhost,P=3306,u=bob,D=db1,t=t1,S=/var/run/mysqld/mysqld.sock",
+		"--execute",
+	}, osc.command(dbUri, "db1", "t1", "ADD COLUMN `c1` int"))
+}
+
+func TestCommandString(t *testing.T) {
+	assert.Equal(t, "gh-ost --user=bob@example.com --max-load=Threads_running=25,Threads_connected=100 --execute",
+		commandString([]string{"gh-ost", "--user=bob@example.com", "--max-load=Threads_running=25,Threads_connected=100", "--execute"}))
+	// arguments with characters special to shells are quoted
+	assert.Equal(t, "gh-ost '--alter=ADD COLUMN `c1` int' '--user=$USER' '--table=t1;rm' '--database=a&b' '--critical-load=*' 'it'\\''s' ''",
+		commandString([]string{"gh-ost", "--alter=ADD COLUMN `c1` int", "--user=$USER", "--table=t1;rm", "--database=a&b", "--critical-load=*", "it's", ""}))
+}
+
+func TestOnlineSchemaChangeRun(t *testing.T) {
+	// fake tool recording its arguments
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args.txt")
+	script := filepath.Join(dir, "gh-ost")
+	err := os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\n", out)), 0755)
+	require.NoError(t, err)
+
+	osc := &OnlineSchemaChange{Tool: OscToolGhost, Path: script}
+	dbUri := &DatabaseUri{Dialect: "mysql", Host: "localhost", User: "root"}
+	err = osc.run(osc.command(dbUri, "db1", "t1", "DROP COLUMN `c1`"), "")
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "--host=localhost --port=3306 --user=root --database=db1 --table=t1 --alter=DROP COLUMN `c1` --execute\n", string(b))
+
+	// the password is passed by the option file, which is removed after the execution
+	err = os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\ncat \"${1#--conf=}\" >> %s\n", out, out)), 0755)
+	require.NoError(t, err)
+	err = osc.run(osc.command(dbUri, "db1", "t1", "DROP COLUMN `c1`"), `p"a\ss`)
+	require.NoError(t, err)
+	b, err = os.ReadFile(out)
+	require.NoError(t, err)
+	lines := strings.SplitN(string(b), "\n", 2)
+	file := strings.TrimPrefix(strings.Fields(lines[0])[0], "--conf=")
+	assert.Equal(t, "[client]\npassword=\"p\\\"a\\\\ss\"\n", lines[1])
+	assert.NoFileExists(t, file)
+
+	// failure of the tool
+	err = os.WriteFile(script, []byte("#!/bin/sh\nexit 1\n"), 0755)
+	require.NoError(t, err)
+	err = osc.run(osc.command(dbUri, "db1", "t1", "DROP COLUMN `c1`"), "")
+	assert.ErrorContains(t, err, "failed to execute gh-ost")
+}
@@ -5,27 +5,57 @@ import (
 	"fmt"
 	"github.com/kota65535/alternator/lib"
 	"github.com/spf13/cobra"
+	"os"
 	"strings"
 )
 
 //go:embed plan.tmpl
 var planUsage string
 
+type PlanParams struct {
+	Out              string
+	Format           string
+	DetailedExitCode bool
+	FailOn           []string
+	Reverse          bool
+	SplitStatements  bool
+}
+
+const (
+	ExitCodeNoChanges      = 0
+	ExitCodeChangesPending = 2
+)
+
 func init() {
+	var params PlanParams
+
 	c := &cobra.Command{
 		Use:   "plan <schema-file> <database-url>",
 		Short: "Show the remote database schema changes required by the local schema file.",
 		Long:  "Show the remote database schema changes required by the local schema file.",
-		Args:  cobra.ExactArgs(2),
+		Args:  envArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
-			PlanCmd(args[0], args[1])
+			args, err := argsWithEnv(args, ArgSchema, ArgUrl)
+			cobra.CheckErr(err)
+			checkFormat(params.Format)
+			kinds := parseKinds(params.FailOn)
+			alt := PlanCmd(args[0], args[1], params)
+			if params.DetailedExitCode || len(kinds) > 0 {
+				os.Exit(planExitCode(alt, kinds))
+			}
 		},
 	}
+	c.Flags().StringVar(&params.Out, "out", "", "Save the plan to the file")
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text or json)")
+	c.Flags().BoolVar(&params.DetailedExitCode, "detailed-exitcode", false, "Return detailed exit codes (0: no changes, 1: error, 2: changes pending)")
+	c.Flags().BoolVar(&params.Reverse, "reverse", false, "Show the statements to revert the changes as well")
+	c.Flags().BoolVar(&params.SplitStatements, "split-statements", false, "Execute each clause by its own ALTER TABLE statement instead of merging clauses altering the same table")
+	c.Flags().StringSliceVar(&params.FailOn, "fail-on", nil, "Return exit code 2 only if changes of the given kinds are pending (add, modify, drop, rename, move)")
 	rootCmd.AddCommand(c)
 	c.SetUsageTemplate(planUsage)
 }
 
-func PlanCmd(path string, uri string) *lib.DatabaseAlterations {
+func PlanCmd(path string, uri string, params PlanParams) *lib.DatabaseAlterations {
 	dbUri, err := NewDatabaseUri(uri)
 	cobra.CheckErr(err)
 
@@ -33,9 +63,65 @@ func PlanCmd(path string, uri string) *lib.DatabaseAlterations {
 	cobra.CheckErr(err)
 	defer alternator.Close()
 
-	alt, _, _, err := alternator.GetAlterationsFromFile(path)
+	sources, err := readSchemaFiles(path)
+	cobra.CheckErr(err)
+	alt, remoteSchemas, _, err := alternator.GetAlterationsFromSources(sources)
 	cobra.CheckErr(err)
+	alt.SetSplitStatements(params.SplitStatements)
+
+	// Save plan
+	statements := alt.Statements()
+	if params.Out != "" {
+		err = writePlanFile(params.Out, &PlanFile{
+			Version:         PlanFileVersion,
+			Fingerprint:     lib.Fingerprint(remoteSchemas),
+			Statements:      statements,
+			Schemas:         sources,
+			RenameThreshold: alternator.RenameThreshold,
+			SplitStatements: params.SplitStatements,
+		})
+		cobra.CheckErr(err)
+	}
+
+	if params.Format == FormatJson {
+		report := lib.NewReport(alt)
+		report.SetAlgorithms(lib.OnlineDdls(alt, alternator.ServerVersion))
+		if params.Reverse && len(report.WithoutReverse()) == 0 {
+			report.ReverseStatements = alt.Reverse().Statements()
+		}
+		printJson(report)
+		if len(statements) == 0 {
+			return nil
+		}
+		return alt
+	}
+
+	if !printAlterations(alt) {
+		return nil
+	}
+	printOnlineDdls(statements, lib.OnlineDdls(alt, alternator.ServerVersion), alternator.ServerVersion)
+	printDestructive(statements, lib.Destructions(alt))
+	printRenameCandidates(alt.RenameCandidates())
+	if params.Reverse && printWithoutReverse(alt) {
+		bPrintln()
+		bPrintln("Statements to revert:")
+		bPrintln()
+		for _, s := range alt.Reverse().Statements() {
+			fmt.Println(s)
+		}
+		printIrreversible(alt)
+	}
+	if params.Out != "" {
+		bPrintln()
+		bPrintf("Saved the plan to %s. Run \"alternator apply %s <database-url>\" to apply it.\n", params.Out, params.Out)
+	}
 
+	return alt
+}
+
+// printAlterations shows the schema diff and the statements to execute.
+// It returns false if no change is required.
+func printAlterations(alt *lib.DatabaseAlterations) bool {
 	// Show diff
 	ePrintln(strings.Repeat("―", width))
 	bPrintln("Schema diff:")
@@ -49,13 +135,126 @@ func PlanCmd(path string, uri string) *lib.DatabaseAlterations {
 	statements := alt.Statements()
 	if len(statements) == 0 {
 		bPrintln("Your database schema is up-to-date! No change required.")
-		return nil
+		return false
 	}
 	bPrintln("Statements to execute:")
 	bPrintln()
-	for _, s := range alt.Statements() {
+	for _, s := range statements {
 		fmt.Println(s)
 	}
+	return true
+}
+
+// printOnlineDdls shows the online DDL algorithms of ALTER TABLE statements
+func printOnlineDdls(statements []string, ddls map[string]lib.OnlineDdl, version lib.ServerVersion) {
+	if len(ddls) == 0 {
+		return
+	}
+	bPrintln()
+	bPrintf("Online DDL algorithms on MySQL %s:\n", version)
+	bPrintln()
+	for _, s := range statements {
+		ddl, ok := ddls[s]
+		if !ok {
+			continue
+		}
+		c := Green
+		switch ddl.Algorithm {
+		case lib.AlgorithmInplace:
+			c = Yellow
+		case lib.AlgorithmCopy:
+			c = Red
+		}
+		c.Fprintf(os.Stderr, "  %-8s", ddl.Algorithm)
+		ePrintln(firstLine(s))
+	}
+}
 
-	return alt
+// printDestructive highlights the statements dropping objects along with their data
+func printDestructive(statements []string, destructions map[string]lib.Destruction) {
+	var destructive []string
+	for _, s := range statements {
+		if _, ok := destructions[s]; ok {
+			destructive = append(destructive, s)
+		}
+	}
+	if len(destructive) == 0 {
+		return
+	}
+	bPrintln()
+	bPrintln("Following statements are destructive, dropping objects along with their data:")
+	bPrintln()
+	for _, s := range destructive {
+		Red.Fprintf(os.Stderr, "  %-14s", destructions[s])
+		ePrintln(firstLine(s))
+	}
+}
+
+// printRenameCandidates shows the pairs of dropped and added tables or columns which may have been renamed,
+// with their similarity scores
+func printRenameCandidates(candidates []*lib.RenameCandidate) {
+	if len(candidates) == 0 {
+		return
+	}
+	bPrintln()
+	bPrintf("Rename candidates, renamed if the score is at least %v (--rename-threshold):\n", renameThreshold)
+	bPrintln()
+	for _, c := range candidates {
+		if c.Renamed {
+			Green.Fprintf(os.Stderr, "  %.2f  %-9s", c.Score, "renamed")
+		} else {
+			Yellow.Fprintf(os.Stderr, "  %.2f  %-9s", c.Score, "drop+add")
+		}
+		ePrintln(c.String())
+	}
+}
+
+// printIrreversible shows the alterations whose data cannot be restored by the reverse statements
+func printIrreversible(alt *lib.DatabaseAlterations) {
+	irreversible := lib.NewReport(alt).Irreversible()
+	if len(irreversible) == 0 {
+		return
+	}
+	bPrintln()
+	bPrintln("Following changes are irreversible, since data lost by them cannot be restored:")
+	for _, r := range irreversible {
+		Yellow.Fprintf(os.Stderr, "  - %s %s %s\n", r.Kind, strings.ReplaceAll(r.Type, "_", " "), r.Name)
+	}
+}
+
+// printWithoutReverse shows the alterations whose reverse statements cannot be built.
+// It returns false if there are any.
+func printWithoutReverse(alt *lib.DatabaseAlterations) bool {
+	withoutReverse := lib.NewReport(alt).WithoutReverse()
+	if len(withoutReverse) == 0 {
+		return true
+	}
+	bPrintln()
+	bPrintln("Following changes cannot be reverted, since the names of their keys or constraints are given by the server. Name them in the schema file:")
+	for _, r := range withoutReverse {
+		Red.Fprintf(os.Stderr, "  - %s %s %s\n", r.Kind, strings.ReplaceAll(r.Type, "_", " "), r.Name)
+	}
+	return false
+}
+
+func parseKinds(strs []string) []lib.Kind {
+	var kinds []lib.Kind
+	for _, s := range strs {
+		k, err := lib.ParseKind(s)
+		cobra.CheckErr(err)
+		kinds = append(kinds, k)
+	}
+	return kinds
+}
+
+// planExitCode returns the exit code of plan command.
+// If kinds are given, only changes of those kinds are regarded as pending.
+func planExitCode(alt *lib.DatabaseAlterations, kinds []lib.Kind) int {
+	if alt == nil || len(alt.Statements()) == 0 {
+		return ExitCodeNoChanges
+	}
+	if len(kinds) > 0 && !lib.NewReport(alt).HasKind(kinds...) {
+		return ExitCodeNoChanges
+	}
+	return ExitCodeChangesPending
 }
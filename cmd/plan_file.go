@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kota65535/alternator/lib"
+	"io/fs"
+	"os"
+	"unicode"
+)
+
+// PlanFileVersion is the version of plan files. Plan files of older versions are rejected, since they lack the schema
+// files required to verify the statements.
+const PlanFileVersion = 2
+
+// PlanFile is a saved result of plan command, which can be applied later by apply command.
+// The alterations are computed again from the schema files and the remote schemas on apply, so that destructive
+// statements and online DDL algorithms are never taken from the file.
+type PlanFile struct {
+	Version int `json:"version"`
+	// Fingerprint of the remote schemas that the plan was computed against
+	Fingerprint string   `json:"fingerprint"`
+	Statements  []string `json:"statements"`
+	// Local schema files that the plan was computed from
+	Schemas         []lib.SchemaSource `json:"schemas"`
+	RenameThreshold float64            `json:"rename_threshold"`
+	SplitStatements bool               `json:"split_statements,omitempty"`
+}
+
+func writePlanFile(path string, plan *PlanFile) error {
+	b, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize plan : %w", err)
+	}
+	err = os.WriteFile(path, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write plan file: %s : %w", path, err)
+	}
+	return nil
+}
+
+// readPlanFile reads a plan file.
+// It returns nil without error if the path is not a plan file, so that it can be distinguished from schema files.
+// A file is a plan file if it begins with "{" except spaces, which SQL schema files never do.
+func readPlanFile(path string) (*PlanFile, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Glob patterns of schema files
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %s : %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %s : %w", path, err)
+	}
+	if !bytes.HasPrefix(bytes.TrimLeftFunc(b, unicode.IsSpace), []byte("{")) {
+		return nil, nil
+	}
+	var plan PlanFile
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %s : %w", path, err)
+	}
+	if plan.Version == 0 {
+		return nil, fmt.Errorf("invalid plan file without version: %s", path)
+	}
+	if plan.Version > PlanFileVersion {
+		return nil, fmt.Errorf("unsupported plan file version: %d", plan.Version)
+	}
+	if plan.Version < PlanFileVersion {
+		return nil, fmt.Errorf("plan file version %d is no longer supported. Run plan again", plan.Version)
+	}
+	return &plan, nil
+}
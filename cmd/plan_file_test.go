@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPlanFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := &PlanFile{
+		Version:     PlanFileVersion,
+		Fingerprint: "abc",
+		Statements:  []string{"DROP DATABASE `db1`;"},
+	}
+	err := writePlanFile(path, plan)
+	require.NoError(t, err)
+
+	actual, err := readPlanFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plan, actual)
+
+	// schema files are not plan files
+	actual, err = readPlanFile(filepath.Join(RootPath, "db", "to.sql"))
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+
+	// corrupt plan files are not read as schema files
+	broken := filepath.Join(t.TempDir(), "broken.json")
+	require.NoError(t, os.WriteFile(broken, []byte(`  {"version": 1, "statements": [`), 0644))
+	_, err = readPlanFile(broken)
+	assert.ErrorContains(t, err, "failed to parse plan file")
+	require.NoError(t, os.WriteFile(broken, []byte(`{"statements": []}`), 0644))
+	_, err = readPlanFile(broken)
+	assert.ErrorContains(t, err, "invalid plan file without version")
+
+	// plan files of older versions are rejected
+	require.NoError(t, os.WriteFile(broken, []byte(`{"version": 1, "statements": []}`), 0644))
+	_, err = readPlanFile(broken)
+	assert.ErrorContains(t, err, "plan file version 1 is no longer supported")
+
+	// glob patterns are not plan files
+	actual, err = readPlanFile(filepath.Join(RootPath, "db", "*.sql"))
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestPlanAlterations(t *testing.T) {
+	config := &parser.GlobalConfig{CharsetToCollation: DefaultCharsetToCollation}
+	alternator := &Alternator{DbUri: &DatabaseUri{}, GlobalConfig: config, Filter: &lib.Filter{}}
+	remoteSchemas, err := alternator.ReadSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int);
+CREATE TABLE t2 (id int PRIMARY KEY);`)
+	require.NoError(t, err)
+	sources := []lib.SchemaSource{{Name: "schema.sql", Body: `
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY);`}}
+	plan := &PlanFile{
+		Version:         PlanFileVersion,
+		Fingerprint:     lib.Fingerprint(remoteSchemas),
+		Statements:      []string{"ALTER TABLE `db1`.`t1` DROP COLUMN `c1`;", "DROP TABLE `db1`.`t2`;"},
+		Schemas:         sources,
+		RenameThreshold: lib.DefaultRenameThreshold,
+	}
+
+	// destructions are computed from the schema files, not taken from the plan file
+	alt, err := planAlterations(alternator, plan, remoteSchemas)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]lib.Destruction{
+		"ALTER TABLE `db1`.`t1` DROP COLUMN `c1`;": lib.DestructionDropColumn,
+		"DROP TABLE `db1`.`t2`;":                   lib.DestructionDropTable,
+	}, lib.Destructions(alt))
+	// the report of saved-plan applies is built from them as well
+	report := lib.NewReport(alt)
+	require.Len(t, report.Databases, 1)
+	assert.Equal(t, plan.Statements, report.Statements)
+
+	// edited plan files are rejected
+	plan.Statements = append(plan.Statements, "DROP DATABASE `db1`;")
+	_, err = planAlterations(alternator, plan, remoteSchemas)
+	assert.ErrorContains(t, err, "statements of the plan do not match its schema files")
+}
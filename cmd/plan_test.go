@@ -35,12 +35,15 @@ func TestPlan(t *testing.T) {
 			// when
 			err := prepareDb(dir, fixture.Database)
 			require.NoError(t, err)
-			alt := PlanCmd(testFile(dir, "to.sql", fixture.Database), url)
+			alt := PlanCmd(testFile(dir, "to.sql", fixture.Database), url, PlanParams{})
 
 			// assert diff
 			s, err := getDiff(dir, fixture.Database)
 			require.NoError(t, err)
 			assert.Equal(t, s, strings.Join(alt.Diff(), "\n"))
+
+			// assert exit code
+			assert.Equal(t, ExitCodeChangesPending, planExitCode(alt, nil))
 		})
 	}
 }
@@ -4,29 +4,59 @@ import (
 	_ "embed"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/kota65535/alternator/lib"
 	"github.com/spf13/cobra"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 //go:embed pull.tmpl
 var pullUsage string
 
+type PullParams struct {
+	Format    string
+	OutputDir string
+}
+
+// DatabaseFileName is the name of the file containing CREATE DATABASE statement in the output directory
+const DatabaseFileName = "_database.sql"
+
+// PulledDatabase is a remote database schema in JSON format
+type PulledDatabase struct {
+	Name       string         `json:"name"`
+	Definition string         `json:"definition"`
+	Tables     []*PulledTable `json:"tables"`
+}
+
+// PulledTable is a remote table schema in JSON format
+type PulledTable struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
 func init() {
+	var params PullParams
+
 	c := &cobra.Command{
 		Use:   "pull <database-url>",
 		Short: "Show the remote database schema.",
 		Long:  "Show the remote database schema.",
-		Args:  cobra.ExactArgs(1),
+		Args:  envArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			PullCmd(args[0])
+			args, err := argsWithEnv(args, ArgUrl)
+			cobra.CheckErr(err)
+			checkFormat(params.Format)
+			PullCmd(args[0], params)
 		},
 	}
+	c.Flags().StringVar(&params.Format, "format", FormatText, "Output format (text or json)")
+	c.Flags().StringVar(&params.OutputDir, "output-dir", "", "Write schema files into the directory, one file per table")
 	rootCmd.AddCommand(c)
 	c.SetUsageTemplate(pullUsage)
 }
 
-func PullCmd(uri string) {
+func PullCmd(uri string, params PullParams) {
 	dbUri, err := NewDatabaseUri(uri)
 	cobra.CheckErr(err)
 
@@ -37,6 +67,39 @@ func PullCmd(uri string) {
 	schemas, err := alternator.FetchSchemas()
 	cobra.CheckErr(err)
 
+	if params.OutputDir != "" {
+		files, err := writeSchemaDir(params.OutputDir, schemas)
+		cobra.CheckErr(err)
+		for _, f := range files {
+			ePrintln(f)
+		}
+		bPrintf("Wrote %d schema files to %s.\n", len(files), params.OutputDir)
+		stale, err := staleSchemaFiles(params.OutputDir, files)
+		cobra.CheckErr(err)
+		if len(stale) > 0 {
+			bPrintln()
+			bPrintln("Following schema files are not of the remote schema, but they are still read by plan and apply.")
+			bPrintln("Remove them if their tables or databases have been dropped:")
+			for _, f := range stale {
+				Yellow.Fprintf(os.Stderr, "  %s\n", f)
+			}
+		}
+		return
+	}
+
+	if params.Format == FormatJson {
+		databases := []*PulledDatabase{}
+		for _, s := range schemas {
+			tables := []*PulledTable{}
+			for _, t := range s.Tables {
+				tables = append(tables, &PulledTable{Name: t.TableName, Definition: t.String()})
+			}
+			databases = append(databases, &PulledDatabase{Name: s.Database.DbName, Definition: s.Database.String(), Tables: tables})
+		}
+		printJson(databases)
+		return
+	}
+
 	// Show remote database schemas
 	ePrintf(strings.Repeat("―", width))
 	if len(schemas) == 0 {
@@ -52,3 +115,69 @@ func PullCmd(uri string) {
 		}
 	}
 }
+
+// writeSchemaDir writes schemas into the directory as <dir>/<database>/_database.sql and <dir>/<database>/<table>.sql.
+// Table files begin with USE statement, so that the directory can be read as schema files again.
+// Database and table names are escaped by schemaFileName.
+func writeSchemaDir(dir string, schemas []*lib.Schema) ([]string, error) {
+	var files []string
+	for _, s := range schemas {
+		dbDir := filepath.Join(dir, schemaFileName(s.Database.DbName))
+		err := os.MkdirAll(dbDir, 0755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory: %s : %w", dbDir, err)
+		}
+
+		path := filepath.Join(dbDir, DatabaseFileName)
+		err = os.WriteFile(path, []byte(s.Database.String()+"\n"), 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write schema file: %s : %w", path, err)
+		}
+		files = append(files, path)
+
+		for _, t := range s.Tables {
+			table := *t
+			table.DbName = ""
+			path := filepath.Join(dbDir, schemaFileName(t.TableName)+".sql")
+			body := fmt.Sprintf("USE `%s`;\n\n%s\n", s.Database.DbName, table.String())
+			err = os.WriteFile(path, []byte(body), 0644)
+			if err != nil {
+				return nil, fmt.Errorf("failed to write schema file: %s : %w", path, err)
+			}
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// schemaFileName escapes the database or table name to be used as a file name.
+// Characters other than letters, digits, "_", "$" and "-" are percent-encoded, as well as the leading "_", so that
+// names never point outside the directory nor collide with _database.sql.
+func schemaFileName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '$' || c == '-' || (c == '_' && i > 0) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// staleSchemaFiles returns the schema files in the directory other than the written ones, such as those of dropped
+// tables written by the previous pull
+func staleSchemaFiles(dir string, written []string) ([]string, error) {
+	files, err := listSchemaFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, f := range files {
+		if !lib.Contains(written, f) {
+			ret = append(ret, f)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/lib"
+	"github.com/kota65535/alternator/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSchemaDir(t *testing.T) {
+	config := &parser.GlobalConfig{CharsetToCollation: DefaultCharsetToCollation}
+	sources, err := readSchemaFiles(filepath.Join(RootPath, "db", "to.sql"))
+	require.NoError(t, err)
+	schemas, err := lib.NewSchemasFromSources(sources, config, hashset.New())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files, err := writeSchemaDir(dir, schemas)
+	require.NoError(t, err)
+	assert.Contains(t, files, filepath.Join(dir, "db1", "_database.sql"))
+	assert.Contains(t, files, filepath.Join(dir, "db3", "t31.sql"))
+
+	b, err := os.ReadFile(filepath.Join(dir, "db3", "t31.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "USE `db3`;\n\nCREATE TABLE `t31`\n(\n    `int1` int NOT NULL,\n    PRIMARY KEY (`int1`)\n);\n", string(b))
+
+	// the directory can be read as schema files again
+	sources, err = readSchemaFiles(dir)
+	require.NoError(t, err)
+	actual, err := lib.NewSchemasFromSources(sources, config, hashset.New())
+	require.NoError(t, err)
+	assert.Equal(t, lib.Fingerprint(schemas), lib.Fingerprint(actual))
+
+	// files of the tables no longer existing are stale
+	stale := filepath.Join(dir, "db3", "dropped.sql")
+	require.NoError(t, os.WriteFile(stale, []byte(""), 0644))
+	files, err = writeSchemaDir(dir, schemas)
+	require.NoError(t, err)
+	staleFiles, err := staleSchemaFiles(dir, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{stale}, staleFiles)
+}
+
+func TestSchemaFileName(t *testing.T) {
+	assert.Equal(t, "users", schemaFileName("users"))
+	assert.Equal(t, "user_logs$1", schemaFileName("user_logs$1"))
+	assert.Equal(t, "%5Fdatabase", schemaFileName("_database"))
+	assert.Equal(t, "%2E%2E%2Fetc", schemaFileName("../etc"))
+	assert.Equal(t, "a%20b", schemaFileName("a b"))
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	_ "embed"
+	"fmt"
+	"github.com/kota65535/alternator/lib"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -17,6 +19,19 @@ var rootUsage string
 var rootCmd = &cobra.Command{
 	Use:  "alternator <command> [options]",
 	Long: "Alternator: Declarative SQL database schema management by human-friendly pure SQL",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cobra.CheckErr(loadEnv(cmd))
+		cobra.CheckErr(filter.Validate())
+		if concurrency < 1 {
+			cobra.CheckErr(fmt.Errorf("concurrency must be at least 1: %d", concurrency))
+		}
+		if !SupportedFetchMethods.Contains(fetchMethod) {
+			cobra.CheckErr(fmt.Errorf("unsupported fetch method: %s", fetchMethod))
+		}
+		if renameThreshold < 0 || renameThreshold > 1 {
+			cobra.CheckErr(fmt.Errorf("rename threshold must be between 0 and 1: %v", renameThreshold))
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -30,16 +45,41 @@ func Execute() {
 
 var (
 	managesAllDatabases bool
-	debug               bool
-	width               int
+	// filter selects databases, tables and columns to manage
+	filter lib.Filter
+	debug  bool
+	width  int
+	// concurrency is the maximum number of queries fetching remote schemas at the same time
+	concurrency int
+	// fetchMethod is how remote table definitions are fetched
+	fetchMethod string
+	// renameThreshold is the minimum similarity score of dropped and added tables or columns to be renamed
+	renameThreshold float64
 )
 
 const MinTermWidth = 80
 
+// DefaultConcurrency is the default maximum number of concurrent queries fetching remote schemas
+const DefaultConcurrency = 8
+
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.PersistentFlags().BoolVar(&managesAllDatabases, "all", false, "manages all user defined databases")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "debug flag")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Maximum number of concurrent queries fetching remote schemas")
+	rootCmd.PersistentFlags().StringVar(&fetchMethod, "fetch-method", FetchMethodShowCreate, "How to fetch remote table definitions (show-create, information-schema)")
+	rootCmd.PersistentFlags().StringSliceVar(&filter.IncludeDatabases, "include-database", nil, "Patterns of databases to manage")
+	rootCmd.PersistentFlags().StringSliceVar(&filter.ExcludeDatabases, "exclude-database", nil, "Patterns of databases not to manage")
+	rootCmd.PersistentFlags().StringSliceVar(&filter.IncludeTables, "include-table", nil, "Patterns of tables to manage")
+	rootCmd.PersistentFlags().StringSliceVar(&filter.ExcludeTables, "exclude-table", nil, "Patterns of tables not to manage")
+	rootCmd.PersistentFlags().StringSliceVar(&filter.ExcludeColumns, "exclude-column", nil, "Patterns of columns not to manage")
+	rootCmd.PersistentFlags().StringVar(&defaultsFile, "defaults-file", "", "Option file to read credentials from instead of ~/.my.cnf")
+	rootCmd.PersistentFlags().StringVar(&loginPath, "login-path", "", "Group of option files to read credentials from in addition to [client]")
+	rootCmd.PersistentFlags().BoolVar(&passwordStdin, "password-stdin", false, "Read the database password from stdin")
+	rootCmd.PersistentFlags().Float64Var(&renameThreshold, "rename-threshold", lib.DefaultRenameThreshold, "Minimum similarity score of dropped and added tables or columns to be renamed")
+	rootCmd.PersistentFlags().StringVar(&historyTable, "history-table", DefaultHistoryTable, "Table recording applies")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", DefaultConfigFile, "Path of the config file")
+	rootCmd.PersistentFlags().StringVar(&envName, "env", "", "Name of the environment defined in the config file")
 	rootCmd.SetUsageTemplate(rootUsage)
 
 	w, _, _ := term.GetSize(int(syscall.Stdin))
@@ -0,0 +1,10 @@
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY COMMENT 'ID',
+    price float COMMENT 'Price', -- alternator:lint-disable no-float-money
+    created_at timestamp COMMENT 'Created time'
+);
+CREATE TABLE t2 (
+    id int COMMENT 'ID'
+);
@@ -1,5 +1 @@
-ALTER TABLE `db1`.`t2` MODIFY COLUMN `varchar1` varchar(10);
-ALTER TABLE `db1`.`t2` CHANGE COLUMN `varchar4` `varchar5` varchar(30);
-ALTER TABLE `db1`.`t2` DROP COLUMN `varchar2`;
-ALTER TABLE `db1`.`t2` MODIFY COLUMN `int1` int NOT NULL AFTER `varchar5`;
-ALTER TABLE `db1`.`t2` ADD COLUMN `varchar3` varchar(10) AFTER `int1`;
\ No newline at end of file
+ALTER TABLE `db1`.`t2` MODIFY COLUMN `varchar1` varchar(10), CHANGE COLUMN `varchar4` `varchar5` varchar(30), DROP COLUMN `varchar2`, MODIFY COLUMN `int1` int NOT NULL AFTER `varchar5`, ADD COLUMN `varchar3` varchar(10) AFTER `int1`;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE `db1`.`t2` ADD CONSTRAINT `c2` FOREIGN KEY `c2` (`int2`) REFERENCES `db2`.`t0` (`int2`);
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE DATABASE db2;
+
+USE db2;
+
+CREATE TABLE `t0`
+(
+    `int1` int,
+    `int2` int,
+    PRIMARY KEY (`int1`),
+    UNIQUE KEY (`int2`)
+);
+
+CREATE DATABASE db1;
+
+USE db1;
+
+CREATE TABLE `t2`
+(
+    `int1` int,
+    `int2` int,
+    # remained, referencing the table in another database
+    CONSTRAINT c1 FOREIGN KEY (`int1`) REFERENCES `db2`.`t0` (`int1`),
+    # added, referencing the table in another database
+    CONSTRAINT c2 FOREIGN KEY (`int2`) REFERENCES `db2`.`t0` (`int2`)
+);
\ No newline at end of file
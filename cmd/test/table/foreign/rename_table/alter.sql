@@ -1,7 +1,3 @@
 ALTER TABLE `db1`.`t0` RENAME TO `db1`.`t3`;
-ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `t1_ibfk_2`;
-ALTER TABLE `db1`.`t1` DROP INDEX `t1_ibfk_2`;
-ALTER TABLE `db1`.`t1` ADD FOREIGN KEY (`int2`) REFERENCES `t3` (`int2`) ON UPDATE CASCADE;
-ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `t1_ibfk_3`;
-ALTER TABLE `db1`.`t1` DROP INDEX `t1_ibfk_3`;
-ALTER TABLE `db1`.`t1` ADD FOREIGN KEY (`int4`) REFERENCES `t3` (`int2`);
\ No newline at end of file
+ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `t1_ibfk_2`, DROP FOREIGN KEY `t1_ibfk_3`;
+ALTER TABLE `db1`.`t1` DROP INDEX `t1_ibfk_2`, ADD FOREIGN KEY (`int2`) REFERENCES `t3` (`int2`) ON UPDATE CASCADE, DROP INDEX `t1_ibfk_3`, ADD FOREIGN KEY (`int4`) REFERENCES `t3` (`int2`);
\ No newline at end of file
@@ -1,4 +1 @@
-ALTER TABLE `db1`.`t1` ALTER INDEX `var2` INVISIBLE;
-ALTER TABLE `db1`.`t1` DROP INDEX `idx1`;
-ALTER TABLE `db1`.`t1` ADD FULLTEXT INDEX `idx1` (`var5`);
-ALTER TABLE `db1`.`t1` RENAME INDEX `idx2` TO `idx3`;
\ No newline at end of file
+ALTER TABLE `db1`.`t1` ALTER INDEX `var2` INVISIBLE, DROP INDEX `idx1`, ADD FULLTEXT INDEX `idx1` (`var5`), RENAME INDEX `idx2` TO `idx3`;
\ No newline at end of file
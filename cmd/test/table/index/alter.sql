@@ -1,4 +1 @@
-ALTER TABLE `db1`.`t1` DROP INDEX `idx1`;
-ALTER TABLE `db1`.`t1` ADD INDEX `idx1` (`int5` DESC);
-ALTER TABLE `db1`.`t1` RENAME INDEX `idx2` TO `idx3`;
-ALTER TABLE `db1`.`t1` ALTER INDEX `int2` INVISIBLE;
\ No newline at end of file
+ALTER TABLE `db1`.`t1` DROP INDEX `idx1`, ADD INDEX `idx1` (`int5` DESC), RENAME INDEX `idx2` TO `idx3`, ALTER INDEX `int2` INVISIBLE;
\ No newline at end of file
@@ -1,8 +1,4 @@
-ALTER TABLE `db1`.`t1` DROP INDEX `idx1`;
-ALTER TABLE `db1`.`t1` RENAME INDEX `idx2` TO `idx3`;
-ALTER TABLE `db1`.`t1` ALTER INDEX `int2` INVISIBLE;
-ALTER TABLE `db1`.`t2` DROP INDEX `idx1`;
-ALTER TABLE `db1`.`t2` RENAME INDEX `idx2` TO `idx3`;
-ALTER TABLE `db1`.`t2` ALTER INDEX `c2` INVISIBLE;
+ALTER TABLE `db1`.`t1` DROP INDEX `idx1`, RENAME INDEX `idx2` TO `idx3`, ALTER INDEX `int2` INVISIBLE;
+ALTER TABLE `db1`.`t2` DROP INDEX `idx1`, RENAME INDEX `idx2` TO `idx3`, ALTER INDEX `c2` INVISIBLE;
 ALTER TABLE `db1`.`t1` ADD UNIQUE KEY `idx2` (`int5`);
 ALTER TABLE `db1`.`t2` ADD UNIQUE KEY `idx2` (`int5`);
\ No newline at end of file
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"github.com/fatih/color"
-	"log"
+	"github.com/spf13/cobra"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -17,6 +20,23 @@ var (
 	Cyan   = color.New(color.FgCyan)
 )
 
+const (
+	FormatText = "text"
+	FormatJson = "json"
+)
+
+func checkFormat(format string) {
+	if format != FormatText && format != FormatJson {
+		cobra.CheckErr(fmt.Errorf("unsupported format: %s", format))
+	}
+}
+
+func printJson(v any) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	cobra.CheckErr(err)
+	fmt.Println(string(b))
+}
+
 func bPrint(a ...any) {
 	Bold.Fprint(os.Stderr, a...)
 }
@@ -70,7 +90,7 @@ func confirm(s string) bool {
 
 		response, err := reader.ReadString('\n')
 		if err != nil {
-			log.Fatal(err)
+			cobra.CheckErr(fmt.Errorf("failed to read the answer from stdin : %w", err))
 		}
 
 		response = strings.ToLower(strings.TrimSpace(response))
@@ -82,3 +102,43 @@ func confirm(s string) bool {
 		}
 	}
 }
+
+// parallelMap applies the function to the items by at most n goroutines, and returns the results in the order of the items.
+// Items not started yet are skipped after any error, and the error of the first item in order is returned.
+func parallelMap[T any, R any](n int, items []T, f func(T) (R, error)) ([]R, error) {
+	if n < 1 {
+		n = 1
+	}
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	indices := make(chan int)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < n && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if failed.Load() {
+					continue
+				}
+				results[i], errs[i] = f(items[i])
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range items {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelMap(t *testing.T) {
+	items := []int{}
+	for i := 0; i < 100; i++ {
+		items = append(items, i)
+	}
+
+	// results are in the order of items, and at most n functions run at the same time
+	var running, maxRunning atomic.Int32
+	results, err := parallelMap(4, items, func(i int) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(100-i) * 10 * time.Microsecond)
+		return fmt.Sprintf("t%d", i), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 100)
+	for i, r := range results {
+		assert.Equal(t, fmt.Sprintf("t%d", i), r)
+	}
+	assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+
+	// the error of the first item in order is returned
+	_, err = parallelMap(4, items, func(i int) (string, error) {
+		if i == 30 || i == 10 {
+			time.Sleep(time.Duration(30-i) * time.Millisecond)
+			return "", fmt.Errorf("error %d", i)
+		}
+		return "", nil
+	})
+	assert.EqualError(t, err, "error 10")
+
+	results, err = parallelMap(4, []int{}, func(i int) (string, error) { return "", nil })
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
@@ -2,12 +2,10 @@ package cmd
 
 import (
 	_ "embed"
-	"fmt"
 	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/kota65535/alternator/lib"
 	"github.com/kota65535/alternator/parser"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 //go:embed validate.tmpl
@@ -18,8 +16,10 @@ func init() {
 		Use:   "validate <schema-file>",
 		Short: "Validate the local schema file.",
 		Long:  "Validate the local schema file.",
-		Args:  cobra.ExactArgs(1),
+		Args:  envArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			args, err := argsWithEnv(args, ArgSchema)
+			cobra.CheckErr(err)
 			ValidateCmd(args[0])
 		},
 	}
@@ -28,10 +28,8 @@ func init() {
 }
 
 func ValidateCmd(path string) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		cobra.CheckErr(fmt.Errorf("failed to read shema file: %s : %w", path, err))
-	}
-	_, err = lib.NewSchemas(string(b), &parser.GlobalConfig{}, hashset.New())
+	sources, err := readSchemaFiles(path)
+	cobra.CheckErr(err)
+	_, err = lib.NewSchemasFromSources(sources, &parser.GlobalConfig{}, hashset.New())
 	cobra.CheckErr(err)
 }
@@ -17,7 +17,9 @@ type Lexer struct {
 	nextPos           Position
 	TokenTypes        []TokenType
 	SkippedTokenTypes []TokenType
-	prevTokens        []*Token
+	// SkipHandler is called with each skipped token, such as comments
+	SkipHandler func(*Token)
+	prevTokens  []*Token
 }
 
 func NewLexer(reader io.Reader, tokenTypes []TokenType, skippedTokenTypes []TokenType) *Lexer {
@@ -133,6 +135,9 @@ func (l *Lexer) skipTokens() {
 			if tok := t.FindToken(l.buf, l.nextPos); tok != nil {
 				logrus.Debugf("skip: '%s'", tok.Literal)
 				l.consumeBuffer(tok)
+				if l.SkipHandler != nil {
+					l.SkipHandler(tok)
+				}
 				skipped = true
 			}
 		}
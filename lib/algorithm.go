@@ -0,0 +1,244 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Algorithm is an online DDL algorithm used by MySQL to execute ALTER TABLE statements
+type Algorithm string
+
+const (
+	AlgorithmInstant Algorithm = "INSTANT"
+	AlgorithmInplace Algorithm = "INPLACE"
+	AlgorithmCopy    Algorithm = "COPY"
+)
+
+// OnlineDdl is the algorithm and the lock level which MySQL uses for an alteration at best
+type OnlineDdl struct {
+	Algorithm Algorithm `json:"algorithm"`
+	Lock      string    `json:"lock,omitempty"`
+}
+
+// Clause returns ALGORITHM and LOCK clauses of ALTER TABLE statement
+func (r OnlineDdl) Clause() string {
+	if r.Lock == "" {
+		return fmt.Sprintf("ALGORITHM=%s", r.Algorithm)
+	}
+	return fmt.Sprintf("ALGORITHM=%s, LOCK=%s", r.Algorithm, r.Lock)
+}
+
+type ServerVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var serverVersionRegexp = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+// ParseServerVersion parses the result of VERSION() function, such as "8.0.32" or "5.7.44-log"
+func ParseServerVersion(s string) (ServerVersion, error) {
+	m := serverVersionRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return ServerVersion{}, fmt.Errorf("invalid server version: %s", s)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+	return ServerVersion{major, minor, patch}, nil
+}
+
+func (r ServerVersion) AtLeast(major int, minor int, patch int) bool {
+	if r.Major != major {
+		return r.Major > major
+	}
+	if r.Minor != minor {
+		return r.Minor > minor
+	}
+	return r.Patch >= patch
+}
+
+func (r ServerVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", r.Major, r.Minor, r.Patch)
+}
+
+// OnlineDdlOf returns the online DDL algorithm and lock level of the table element alteration on the server version,
+// according to https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html.
+// It returns false if the alteration is not executed by ALTER TABLE statement of table elements.
+func OnlineDdlOf(a Alteration, version ServerVersion) (OnlineDdl, bool) {
+	instant := OnlineDdl{Algorithm: AlgorithmInstant}
+	inplace := OnlineDdl{Algorithm: AlgorithmInplace, Lock: "NONE"}
+	copying := OnlineDdl{Algorithm: AlgorithmCopy, Lock: "SHARED"}
+
+	switch v := a.(type) {
+	case *AddedColumn:
+		// Columns can be added at any position instantly since 8.0.29
+		if version.AtLeast(8, 0, 29) && !isGeneratedColumn(v.This) {
+			return instant, true
+		}
+		return inplace, true
+	case *DroppedColumn:
+		if version.AtLeast(8, 0, 29) && !isGeneratedColumn(v.This) {
+			return instant, true
+		}
+		return inplace, true
+	case *RenamedColumn:
+		// Renamed by a hint along with modification or move
+		if !v.From.EqualsExceptColumnName(*v.To) {
+			return OnlineDdlOf(&ModifiedColumn{From: v.From, To: v.To}, version)
+		}
+		if v.Moved {
+			return OnlineDdlOf(&MovedColumn{From: v.From, To: v.To, After: v.After}, version)
+		}
+		if version.AtLeast(8, 0, 28) {
+			return instant, true
+		}
+		return inplace, true
+	case *ModifiedColumn:
+		if onlyDefaultChanged(v.From, v.To) {
+			if version.AtLeast(8, 0, 0) {
+				return instant, true
+			}
+			return inplace, true
+		}
+		if fmt.Sprint(v.From.DataType) == fmt.Sprint(v.To.DataType) || isVarcharExtended(v.From, v.To) {
+			return inplace, true
+		}
+		return copying, true
+	case *MovedColumn:
+		return inplace, true
+	case *RenamedIndex, *RenamedUniqueKey, *RenamedFullTextIndex:
+		if version.AtLeast(8, 0, 0) {
+			return instant, true
+		}
+		return inplace, true
+	case *AddedIndex, *DroppedIndex, *ModifiedIndex, *AddedUniqueKey, *DroppedUniqueKey, *ModifiedUniqueKey,
+		*DroppedFullTextIndex, *AddedPrimaryKey, *ModifiedPrimaryKey, *DroppedForeignKey, *DroppedCheckConstraint:
+		return inplace, true
+	case *AddedFullTextIndex, *ModifiedFullTextIndex:
+		// Concurrent DML is not permitted while building full-text indexes
+		return OnlineDdl{Algorithm: AlgorithmInplace, Lock: "SHARED"}, true
+	case *DroppedPrimaryKey:
+		return copying, true
+	case *AddedForeignKey:
+		// INPLACE is supported only when foreign_key_checks is disabled
+		return copying, true
+	case *AddedCheckConstraint, *ModifiedCheckConstraint:
+		return copying, true
+	case *TableOptionAlterations:
+		if len(v.Statements()) == 0 {
+			return OnlineDdl{}, false
+		}
+		for _, s := range v.Statements() {
+			if strings.HasPrefix(s, "ENGINE ") {
+				return copying, true
+			}
+		}
+		return inplace, true
+	}
+	return OnlineDdl{}, false
+}
+
+// OnlineDdls returns the online DDL algorithms and lock levels of ALTER TABLE statements, keyed by the statements.
+// Statements of each clause are also included even if clauses are merged into one statement.
+// A merged statement is executed by the slowest algorithm and the strongest lock among its clauses.
+func OnlineDdls(alt *DatabaseAlterations, version ServerVersion) map[string]OnlineDdl {
+	var tables []*TableAlterations
+	for _, d := range alt.Added {
+		tables = append(tables, d.Tables)
+	}
+	for _, d := range alt.Modified {
+		tables = append(tables, d.Tables)
+	}
+	for _, d := range alt.Retained {
+		tables = append(tables, &d.Tables)
+	}
+
+	ret := map[string]OnlineDdl{}
+	for _, t := range tables {
+		for _, g := range t.statementGroups() {
+			for _, a := range g.alterations {
+				if ddl, ok := OnlineDdlOf(a, version); ok {
+					for _, s := range completeStatements(a, a.Prefix()) {
+						ret[s] = ddl
+					}
+				}
+			}
+			if ddl, ok := g.onlineDdl(version); ok {
+				ret[g.Statement()] = ddl
+			}
+		}
+	}
+	return ret
+}
+
+// onlineDdl returns the online DDL of the statement executing the alterations.
+// It returns false if none of them is classified. If only some of them are, the statement is regarded as COPY,
+// since the others may require it.
+func (r statementGroup) onlineDdl(version ServerVersion) (OnlineDdl, bool) {
+	merged := OnlineDdl{Algorithm: AlgorithmInstant}
+	classified, unclassified := 0, 0
+	for _, a := range r.alterations {
+		ddl, ok := OnlineDdlOf(a, version)
+		if !ok {
+			unclassified++
+			continue
+		}
+		classified++
+		merged = merged.merge(ddl)
+	}
+	if classified == 0 {
+		return OnlineDdl{}, false
+	}
+	if unclassified > 0 {
+		merged = merged.merge(OnlineDdl{Algorithm: AlgorithmCopy, Lock: "SHARED"})
+	}
+	return merged, true
+}
+
+var algorithmOrder = map[Algorithm]int{AlgorithmInstant: 0, AlgorithmInplace: 1, AlgorithmCopy: 2}
+
+var lockOrder = map[string]int{"": 0, "NONE": 1, "SHARED": 2, "EXCLUSIVE": 3}
+
+// merge returns the online DDL executing both of the alterations in one statement
+func (r OnlineDdl) merge(o OnlineDdl) OnlineDdl {
+	if algorithmOrder[o.Algorithm] > algorithmOrder[r.Algorithm] {
+		r.Algorithm = o.Algorithm
+	}
+	if lockOrder[o.Lock] > lockOrder[r.Lock] {
+		r.Lock = o.Lock
+	}
+	return r
+}
+
+func isGeneratedColumn(c *parser.ColumnDefinition) bool {
+	return c.ColumnOptions.GeneratedAs != ""
+}
+
+func onlyDefaultChanged(from *parser.ColumnDefinition, to *parser.ColumnDefinition) bool {
+	c := *to
+	c.ColumnOptions.Default = from.ColumnOptions.Default
+	return from.Equals(c)
+}
+
+// isVarcharExtended returns true if the VARCHAR column length is extended without changing the number of length bytes
+func isVarcharExtended(from *parser.ColumnDefinition, to *parser.ColumnDefinition) bool {
+	t1, ok1 := from.DataType.(parser.StringType)
+	t2, ok2 := to.DataType.(parser.StringType)
+	if !ok1 || !ok2 || t1.Name != "varchar" || t2.Name != "varchar" {
+		return false
+	}
+	if t1.ActualCharset() != t2.ActualCharset() || t1.ActualCollate() != t2.ActualCollate() {
+		return false
+	}
+	l1, err1 := strconv.Atoi(t1.FieldLen)
+	l2, err2 := strconv.Atoi(t2.FieldLen)
+	if err1 != nil || err2 != nil || l1 > l2 {
+		return false
+	}
+	// Assume the max length of characters is 4 bytes, which is the case of utf8mb4
+	return l1*4 < 256 == (l2*4 < 256)
+}
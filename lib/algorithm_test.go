@@ -0,0 +1,128 @@
+package lib
+
+import (
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	v, err := ParseServerVersion("5.7.44-log")
+	require.NoError(t, err)
+	assert.Equal(t, ServerVersion{5, 7, 44}, v)
+	assert.True(t, v.AtLeast(5, 7, 0))
+	assert.False(t, v.AtLeast(8, 0, 0))
+
+	_, err = ParseServerVersion("unknown")
+	assert.Error(t, err)
+}
+
+func TestOnlineDdls(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 int,
+    c2 varchar(10),
+    c3 varchar(10) DEFAULT 'a',
+    c4 int,
+    INDEX idx1 (c1)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 bigint,
+    c2 varchar(20),
+    c3 varchar(10) DEFAULT 'b',
+    c5 int,
+    FULLTEXT INDEX ft1 (c2)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	alt := NewDatabaseAlterations(from, to)
+
+	algorithms := func(version ServerVersion) map[string]Algorithm {
+		ret := map[string]Algorithm{}
+		for s, d := range OnlineDdls(alt, version) {
+			ret[s] = d.Algorithm
+		}
+		return ret
+	}
+	// A merged statement is executed by the slowest algorithm among its clauses
+	merged := "ALTER TABLE `db1`.`t1` MODIFY COLUMN `c1` bigint, MODIFY COLUMN `c2` varchar(20), " +
+		"MODIFY COLUMN `c3` varchar(10) DEFAULT 'b', CHANGE COLUMN `c4` `c5` int, DROP INDEX `idx1`, " +
+		"ADD FULLTEXT INDEX `ft1` (`c2`);"
+	assert.Equal(t, []string{merged}, alt.Statements())
+
+	assert.Equal(t, map[string]Algorithm{
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c1` bigint;":                  AlgorithmCopy,
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c2` varchar(20);":             AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c3` varchar(10) DEFAULT 'b';": AlgorithmInstant,
+		"ALTER TABLE `db1`.`t1` CHANGE COLUMN `c4` `c5` int;":                AlgorithmInstant,
+		"ALTER TABLE `db1`.`t1` DROP INDEX `idx1`;":                          AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` ADD FULLTEXT INDEX `ft1` (`c2`);":            AlgorithmInplace,
+		merged: AlgorithmCopy,
+	}, algorithms(ServerVersion{8, 0, 32}))
+
+	assert.Equal(t, map[string]Algorithm{
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c1` bigint;":                  AlgorithmCopy,
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c2` varchar(20);":             AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `c3` varchar(10) DEFAULT 'b';": AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` CHANGE COLUMN `c4` `c5` int;":                AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` DROP INDEX `idx1`;":                          AlgorithmInplace,
+		"ALTER TABLE `db1`.`t1` ADD FULLTEXT INDEX `ft1` (`c2`);":            AlgorithmInplace,
+		merged: AlgorithmCopy,
+	}, algorithms(ServerVersion{5, 7, 44}))
+}
+
+func TestOnlineDdlsMerged(t *testing.T) {
+	ddl := OnlineDdl{Algorithm: AlgorithmInstant}.
+		merge(OnlineDdl{Algorithm: AlgorithmInplace, Lock: "NONE"}).
+		merge(OnlineDdl{Algorithm: AlgorithmInplace, Lock: "SHARED"})
+	assert.Equal(t, OnlineDdl{Algorithm: AlgorithmInplace, Lock: "SHARED"}, ddl)
+}
+
+func TestOnlineDdlsUnclassified(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 int
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 int,
+    c2 int,
+    INDEX idx1 (c1)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	alt := NewDatabaseAlterations(from, to)
+	version := ServerVersion{8, 0, 32}
+
+	merged := "ALTER TABLE `db1`.`t1` ADD COLUMN `c2` int AFTER `c1`, ADD INDEX `idx1` (`c1`);"
+	assert.Equal(t, OnlineDdl{Algorithm: AlgorithmInplace, Lock: "NONE"}, OnlineDdls(alt, version)[merged])
+
+	groups := alt.Retained[0].Tables.statementGroups()
+	require.Len(t, groups, 1)
+	require.Equal(t, merged, groups[0].Statement())
+
+	// A merged statement with a clause not classified may require COPY
+	g := *groups[0]
+	g.alterations = append(g.alterations, &DroppedTable{})
+	ddl, ok := g.onlineDdl(version)
+	assert.True(t, ok)
+	assert.Equal(t, OnlineDdl{Algorithm: AlgorithmCopy, Lock: "SHARED"}, ddl)
+
+	// A statement without any classified clause has no online DDL
+	_, ok = statementGroup{alterations: []Alteration{&DroppedTable{}}}.onlineDdl(version)
+	assert.False(t, ok)
+}
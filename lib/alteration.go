@@ -1,5 +1,10 @@
 package lib
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Alteration is general type of all SQL alterations, providing interface to get statements and diff strings.
 type Alteration interface {
 	// Statements returns SQL statements executing the alteration
@@ -74,3 +79,89 @@ func (r Dependent) DependsOn() []Alteration {
 func (r *Dependent) AddDependsOn(a Alteration) {
 	r.dependsOn = append(r.dependsOn, a)
 }
+
+// Kind is a kind of alteration
+type Kind string
+
+const (
+	KindAdded    Kind = "added"
+	KindModified Kind = "modified"
+	KindDropped  Kind = "dropped"
+	KindRenamed  Kind = "renamed"
+	KindMoved    Kind = "moved"
+	KindRetained Kind = "retained"
+)
+
+// KindOf returns the kind of the alteration
+func KindOf(a Alteration) Kind {
+	switch a.(type) {
+	case *AddedDatabase, *AddedTable, *AddedColumn, *AddedPrimaryKey, *AddedUniqueKey, *AddedIndex,
+		*AddedFullTextIndex, *AddedForeignKey, *AddedCheckConstraint:
+		return KindAdded
+	case *ModifiedDatabase, *ModifiedTable, *ModifiedColumn, *ModifiedPrimaryKey, *ModifiedUniqueKey, *ModifiedIndex,
+		*ModifiedFullTextIndex, *ModifiedCheckConstraint:
+		return KindModified
+	case *DroppedDatabase, *DroppedTable, *DroppedColumn, *DroppedPrimaryKey, *DroppedUniqueKey, *DroppedIndex,
+		*DroppedFullTextIndex, *DroppedForeignKey, *DroppedCheckConstraint:
+		return KindDropped
+	case *RenamedTable, *RenamedColumn, *RenamedUniqueKey, *RenamedIndex, *RenamedFullTextIndex:
+		return KindRenamed
+	case *MovedColumn:
+		return KindMoved
+	case *DatabaseOptionAlterations, *TableOptionAlterations:
+		// Option alterations always exist even if nothing is changed
+		if len(a.Statements()) == 0 {
+			return KindRetained
+		}
+		return KindModified
+	default:
+		return KindRetained
+	}
+}
+
+// ParseKind parses a kind of alteration, which can be given in both verb and past participle forms (e.g. drop, dropped)
+func ParseKind(s string) (Kind, error) {
+	switch strings.ToLower(s) {
+	case "add", "added":
+		return KindAdded, nil
+	case "modify", "modified":
+		return KindModified, nil
+	case "drop", "dropped":
+		return KindDropped, nil
+	case "rename", "renamed":
+		return KindRenamed, nil
+	case "move", "moved":
+		return KindMoved, nil
+	}
+	return "", fmt.Errorf("unknown alteration kind: %s", s)
+}
+
+// IsIrreversible returns true if the alteration loses data, so that its reverse alteration cannot restore it
+func IsIrreversible(a Alteration) bool {
+	switch v := a.(type) {
+	case *DroppedDatabase, *DroppedTable, *DroppedColumn:
+		return true
+	case *ModifiedColumn:
+		// Values may be truncated or converted by changing data types
+		return fmt.Sprint(v.From.DataType) != fmt.Sprint(v.To.DataType)
+	}
+	return false
+}
+
+// HasReverse returns false if the reverse alteration cannot be built, since the alteration adds a key or a constraint
+// without name, whose name is given by the server on execution, and so the reverse statement cannot drop it
+func HasReverse(a Alteration) bool {
+	switch v := a.(type) {
+	case *AddedUniqueKey:
+		return v.This.IndexName != ""
+	case *AddedIndex:
+		return v.This.IndexName != ""
+	case *AddedFullTextIndex:
+		return v.This.IndexName != ""
+	case *AddedForeignKey:
+		return v.This.ConstraintName != "" && v.This.IndexName != ""
+	case *AddedCheckConstraint:
+		return v.This.ConstraintName != ""
+	}
+	return true
+}
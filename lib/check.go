@@ -62,7 +62,8 @@ func NewCheckConstraintAlterations(
 				This:       t1,
 				Sequential: Sequential{checkConstraintOrder[s]},
 			})
-		} else if t2.ConstraintName != "" && t1.ConstraintName != t2.ConstraintName {
+		} else if t1.ConstraintName != "" && t2.ConstraintName != "" && t1.ConstraintName != t2.ConstraintName {
+			// Recreated only if both names are known, since the local one without name is not renamed on reverse
 			dropped = append(dropped, &DroppedCheckConstraint{
 				This:       fromMap[s],
 				Sequential: Sequential{checkConstraintOrder[s]},
@@ -186,11 +187,15 @@ type ModifiedCheckConstraint struct {
 
 func (r ModifiedCheckConstraint) Statements() []string {
 	constraintName := r.From.ConstraintName
+	// The constraint keeps its name, which only the remote side may have on reverse alterations
+	if constraintName == "" {
+		constraintName = r.To.ConstraintName
+	}
 	if constraintName == "" {
 		constraintName = fmt.Sprintf("<unknown constraint name of '%s'>", r.From.Check)
 	}
 	return []string{fmt.Sprintf("ALTER CHECK `%s`%s", constraintName,
-		optS(r.To.CheckConstraintOptions.Diff(r.From.CheckConstraintOptions).String(), " %s"))}
+		optS(checkConstraintOptionsDiff(r.From.CheckConstraintOptions, r.To.CheckConstraintOptions).String(), " %s"))}
 }
 
 func (r ModifiedCheckConstraint) Diff() []string {
@@ -301,5 +306,17 @@ func checkConstraintDefsEqual(from parser.CheckConstraintDefinition, to parser.C
 	tc := to.ConstraintName
 	from.ConstraintName = ""
 	to.ConstraintName = ""
+	from.RenamedFrom = ""
+	to.RenamedFrom = ""
 	return reflect.DeepEqual(from, to) && (tc == "" || fc == tc)
 }
+
+// checkConstraintOptionsDiff returns the check constraint options to alter, in which the enforcement is reset to the
+// default explicitly
+func checkConstraintOptionsDiff(from parser.CheckConstraintOptions, to parser.CheckConstraintOptions) parser.CheckConstraintOptions {
+	ret := to.Diff(from)
+	if ret.Enforcement == "" && from.Enforcement != "" {
+		ret.Enforcement = "ENFORCED"
+	}
+	return ret
+}
@@ -13,10 +13,17 @@ type ColumnAlterations struct {
 	Moved       []*MovedColumn
 	Retained    []*RetainedColumn
 	ColumnOrder map[string]int
-	alterations []Alteration
+	// RenameCandidates are the pairs of dropped and added columns which may have been renamed
+	RenameCandidates []*RenameCandidate
+	alterations      []Alteration
 }
 
-func NewColumnAlterations(from []*parser.ColumnDefinition, to []*parser.ColumnDefinition) ColumnAlterations {
+// NewColumnAlterations returns the column alterations. Renames are the new column names keyed by the old ones, which
+// are renamed in addition to the ones given by rename hints.
+func NewColumnAlterations(from []*parser.ColumnDefinition, to []*parser.ColumnDefinition, renames map[string]string) ColumnAlterations {
+
+	// Columns are compared with the old ones given by rename hints as if they had already been renamed
+	from, hinted := applyColumnRenameHints(from, to, renames)
 
 	fromColumnNames := map[string][]*parser.ColumnDefinition{}
 	toColumnNames := map[string][]*parser.ColumnDefinition{}
@@ -160,6 +167,26 @@ func NewColumnAlterations(from []*parser.ColumnDefinition, to []*parser.ColumnDe
 		}
 	}
 
+	// Columns renamed by hints or similarity are renamed along with their modification and move
+	for _, c := range retained {
+		if o, ok := hinted[c.From]; ok {
+			renamed = append(renamed, &RenamedColumn{From: o, To: c.To, Sequential: c.Sequential})
+		}
+	}
+	for _, c := range modified {
+		if o, ok := hinted[c.From]; ok {
+			renamed = append(renamed, &RenamedColumn{From: o, To: c.To, Sequential: c.Sequential})
+		}
+	}
+	for _, c := range moved {
+		if o, ok := hinted[c.From]; ok {
+			renamed = append(renamed, &RenamedColumn{From: o, To: c.To, Moved: true, After: c.After, Sequential: c.Sequential})
+		}
+	}
+	retained = RemoveIf(retained, func(c *RetainedColumn) bool { return hinted[c.From] != nil })
+	modified = RemoveIf(modified, func(c *ModifiedColumn) bool { return hinted[c.From] != nil })
+	moved = RemoveIf(moved, func(c *MovedColumn) bool { return hinted[c.From] != nil })
+
 	var added []*AddedColumn
 	var dropped []*DroppedColumn
 	for _, a := range addedOrMoved {
@@ -187,6 +214,22 @@ func NewColumnAlterations(from []*parser.ColumnDefinition, to []*parser.ColumnDe
 	}
 }
 
+// actualRenameCandidates returns the candidates actually renamed, or dropped and added
+func (r *ColumnAlterations) actualRenameCandidates(candidates []*RenameCandidate) []*RenameCandidate {
+	ret := []*RenameCandidate{}
+	for _, c := range candidates {
+		renamed := ContainsIf(r.Renamed, func(a *RenamedColumn) bool {
+			return a.From.ColumnName == c.From && a.To.ColumnName == c.To
+		})
+		dropped := ContainsIf(r.Dropped, func(a *DroppedColumn) bool { return a.This.ColumnName == c.From })
+		added := ContainsIf(r.Added, func(a *AddedColumn) bool { return a.This.ColumnName == c.To })
+		if (c.Renamed && renamed) || (!c.Renamed && dropped && added) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
 func (r ColumnAlterations) Statements() []string {
 	ret := []string{}
 	for _, b := range r.Alterations() {
@@ -376,12 +419,22 @@ func (r DroppedColumn) Id() string {
 type RenamedColumn struct {
 	From *parser.ColumnDefinition
 	To   *parser.ColumnDefinition
+	// Moved is true if the column is also moved after the After column, or to the first if After is nil
+	Moved bool
+	After *parser.ColumnDefinition
 	Sequential
 	Dependent
 	Prefixable
 }
 
 func (r RenamedColumn) Statements() []string {
+	if r.Moved {
+		columnPos := "FIRST"
+		if r.After != nil {
+			columnPos = fmt.Sprintf("AFTER `%s`", r.After.ColumnName)
+		}
+		return []string{fmt.Sprintf("CHANGE COLUMN\t`%s` %s", r.From.ColumnName, r.To.StringWithPos(columnPos))}
+	}
 	return []string{fmt.Sprintf("CHANGE COLUMN\t`%s` %s", r.From.ColumnName, r.To.String())}
 }
 
@@ -455,3 +508,51 @@ func getColumnOrder(from []*parser.ColumnDefinition, to []*parser.ColumnDefiniti
 	}
 	return ret
 }
+
+// applyColumnRenameHints returns the 'from' columns whose names are replaced with the new ones given by the rename hints
+// of the columns and the renames, and the original columns keyed by the replaced ones.
+// A hint is ignored if the old column does not exist, or either name is used by both sides.
+func applyColumnRenameHints(from []*parser.ColumnDefinition, to []*parser.ColumnDefinition, renames map[string]string) ([]*parser.ColumnDefinition, map[*parser.ColumnDefinition]*parser.ColumnDefinition) {
+	fromNames := map[string]bool{}
+	toNames := map[string]bool{}
+	for _, c := range from {
+		fromNames[c.ColumnName] = true
+	}
+	for _, c := range to {
+		toNames[c.ColumnName] = true
+	}
+	newNames := map[string]string{}
+	usedNames := map[string]bool{}
+	hint := func(old string, name string) {
+		if old == "" || !fromNames[old] || toNames[old] || fromNames[name] || !toNames[name] || newNames[old] != "" || usedNames[name] {
+			return
+		}
+		newNames[old] = name
+		usedNames[name] = true
+	}
+	for _, c := range to {
+		hint(c.RenamedFrom, c.ColumnName)
+	}
+	// Hints of 'from' columns are the ones renamed back, such as on reverting
+	for _, c := range from {
+		hint(c.ColumnName, c.RenamedFrom)
+	}
+	for _, c := range from {
+		hint(c.ColumnName, renames[c.ColumnName])
+	}
+
+	ret := []*parser.ColumnDefinition{}
+	hinted := map[*parser.ColumnDefinition]*parser.ColumnDefinition{}
+	for _, c := range from {
+		name, ok := newNames[c.ColumnName]
+		if !ok {
+			ret = append(ret, c)
+			continue
+		}
+		renamed := *c
+		renamed.ColumnName = name
+		ret = append(ret, &renamed)
+		hinted[&renamed] = c
+	}
+	return ret, hinted
+}
@@ -14,9 +14,21 @@ type DatabaseAlterations struct {
 	Dropped     []*DroppedDatabase
 	Retained    []*RetainedDatabase
 	alterations []Alteration
+	from        []*Schema
+	to          []*Schema
+	split       bool
+	// Dropped and added tables or columns are renamed if their similarity score is at least this
+	renameThreshold float64
 }
 
 func NewDatabaseAlterations(from []*Schema, to []*Schema) *DatabaseAlterations {
+	return NewDatabaseAlterationsWithRenameThreshold(from, to, DefaultRenameThreshold)
+}
+
+// NewDatabaseAlterationsWithRenameThreshold returns the database alterations, in which dropped and added tables or
+// columns are renamed if their similarity score is at least the threshold.
+// The threshold ranges from 0 to 1, and DefaultRenameThreshold renames only the ones equal except their names.
+func NewDatabaseAlterationsWithRenameThreshold(from []*Schema, to []*Schema, threshold float64) *DatabaseAlterations {
 
 	fromMap := map[string]*Schema{}
 	fromSet := linkedhashset.New()
@@ -41,7 +53,7 @@ func NewDatabaseAlterations(from []*Schema, to []*Schema) *DatabaseAlterations {
 	for _, v := range difference(fromSet, toSet).Values() {
 		s := v.(string)
 		t1 := fromMap[s].Tables
-		tableAlterations := NewTableAlterations(t1, []*parser.CreateTableStatement{})
+		tableAlterations := NewTableAlterations(t1, []*parser.CreateTableStatement{}, threshold)
 		dropped = append(dropped, &DroppedDatabase{
 			This:       fromMap[s].Database,
 			Tables:     &tableAlterations,
@@ -52,7 +64,7 @@ func NewDatabaseAlterations(from []*Schema, to []*Schema) *DatabaseAlterations {
 	for _, v := range difference(toSet, fromSet).Values() {
 		s := v.(string)
 		t2 := toMap[s].Tables
-		tableAlterations := NewTableAlterations([]*parser.CreateTableStatement{}, t2)
+		tableAlterations := NewTableAlterations([]*parser.CreateTableStatement{}, t2, threshold)
 		added = append(added, &AddedDatabase{
 			This:       toMap[s].Database,
 			Tables:     &tableAlterations,
@@ -66,7 +78,7 @@ func NewDatabaseAlterations(from []*Schema, to []*Schema) *DatabaseAlterations {
 		d2 := toMap[s].Database
 		t1 := fromMap[s].Tables
 		t2 := toMap[s].Tables
-		alteredTables := NewTableAlterations(t1, t2)
+		alteredTables := NewTableAlterations(t1, t2, threshold)
 		if databasesEqual(d1, d2) {
 			retained = append(retained, &RetainedDatabase{
 				This:       d2,
@@ -88,13 +100,63 @@ func NewDatabaseAlterations(from []*Schema, to []*Schema) *DatabaseAlterations {
 	}
 
 	return &DatabaseAlterations{
-		Added:    added,
-		Modified: modified,
-		Dropped:  dropped,
-		Retained: retained,
+		Added:           added,
+		Modified:        modified,
+		Dropped:         dropped,
+		Retained:        retained,
+		from:            from,
+		to:              to,
+		renameThreshold: threshold,
 	}
 }
 
+// Reverse returns the inverse alterations, which turn the schemas after alterations back into the ones before.
+// They are the alterations from the schemas after alterations to the ones before, in which modified keys and
+// constraints are referred by their remote names.
+// Keys and constraints added without names cannot be dropped by them, since the names are given by the server on
+// execution. Such alterations are reported by HasReverse, and the reverse statements should not be used if any.
+func (r *DatabaseAlterations) Reverse() *DatabaseAlterations {
+	ret := NewDatabaseAlterationsWithRenameThreshold(r.to, r.from, r.renameThreshold)
+	ret.SetSplitStatements(r.split)
+	return ret
+}
+
+// RenameCandidates returns the pairs of dropped and added tables or columns which may have been renamed, with their
+// similarity scores. The ones scored below the threshold are dropped and added.
+func (r *DatabaseAlterations) RenameCandidates() []*RenameCandidate {
+	ret := []*RenameCandidate{}
+	for _, t := range r.tableAlterations() {
+		ret = append(ret, t.RenameCandidates()...)
+	}
+	return ret
+}
+
+// SetSplitStatements sets whether each clause of table alterations is executed by its own ALTER TABLE statement.
+// By default, clauses altering the same table are merged into one statement as long as their dependencies allow.
+func (r *DatabaseAlterations) SetSplitStatements(split bool) {
+	r.split = split
+	for _, t := range r.tableAlterations() {
+		t.split = split
+	}
+}
+
+func (r *DatabaseAlterations) tableAlterations() []*TableAlterations {
+	var ret []*TableAlterations
+	for _, d := range r.Added {
+		ret = append(ret, d.Tables)
+	}
+	for _, d := range r.Modified {
+		ret = append(ret, d.Tables)
+	}
+	for _, d := range r.Dropped {
+		ret = append(ret, d.Tables)
+	}
+	for _, d := range r.Retained {
+		ret = append(ret, &d.Tables)
+	}
+	return ret
+}
+
 func (r *DatabaseAlterations) Statements() []string {
 	ret := []string{}
 	for _, a := range r.Alterations() {
@@ -197,11 +259,17 @@ type ModifiedDatabase struct {
 }
 
 func (r ModifiedDatabase) Statements() []string {
+	var ret []string
+	ret = append(ret, r.optionStatements()...)
+	ret = append(ret, r.Tables.Statements()...)
+	return ret
+}
+
+func (r ModifiedDatabase) optionStatements() []string {
 	var ret []string
 	for _, s := range r.DbOptions.Statements() {
 		ret = append(ret, fmt.Sprintf("ALTER DATABASE `%s` %s;", r.From.DbName, s))
 	}
-	ret = append(ret, r.Tables.Statements()...)
 	return ret
 }
 
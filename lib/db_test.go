@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -43,3 +44,47 @@ func TestGetAlteredDatabases(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(b4), strings.Join(diffTo, "\n"))
 }
+
+func TestReverseDatabases(t *testing.T) {
+	alt := getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")
+	statements := alt.Reverse().Statements()
+	for _, s := range statements {
+		fmt.Println(s)
+	}
+
+	b, err := os.ReadFile("test/db/reverse.sql")
+	require.NoError(t, err)
+	assert.Equal(t, string(b), strings.Join(statements, "\n"))
+
+	// reverse of reverse is the original
+	assert.Equal(t, alt.Statements(), alt.Reverse().Reverse().Statements())
+}
+
+func TestReverseWithoutNames(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int, c2 int, CONSTRAINT chk1 CHECK (c2 > 0));`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int, c2 int, INDEX (c1), CHECK (c2 > 0) NOT ENFORCED);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	alt := NewDatabaseAlterations(from, to)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` ADD INDEX (`c1`), ALTER CHECK `chk1` NOT ENFORCED;",
+	}, alt.Statements())
+
+	// The modified check constraint is referred by the remote name, while the index added without name cannot be dropped
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` DROP INDEX `<unknown index name of 'INDEX (`c1`)'>`, ALTER CHECK `chk1` ENFORCED;",
+	}, alt.Reverse().Statements())
+	withoutReverse := NewReport(alt).WithoutReverse()
+	require.Len(t, withoutReverse, 1)
+	assert.Equal(t, "index", withoutReverse[0].Type)
+	assert.Equal(t, "ALTER TABLE `db1`.`t1` ADD INDEX (`c1`);", withoutReverse[0].Statements[0])
+
+	assert.Empty(t, NewReport(getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")).WithoutReverse())
+}
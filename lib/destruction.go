@@ -0,0 +1,56 @@
+package lib
+
+// Destruction is a kind of destructive alteration, which drops objects along with their data
+type Destruction string
+
+const (
+	DestructionDropDatabase Destruction = "drop-database"
+	DestructionDropTable    Destruction = "drop-table"
+	DestructionDropColumn   Destruction = "drop-column"
+)
+
+// DestructionOf returns the kind of destruction of the alteration.
+// It returns false if the alteration is not destructive.
+func DestructionOf(a Alteration) (Destruction, bool) {
+	switch a.(type) {
+	case *DroppedDatabase:
+		return DestructionDropDatabase, true
+	case *DroppedTable:
+		return DestructionDropTable, true
+	case *DroppedColumn:
+		return DestructionDropColumn, true
+	}
+	return "", false
+}
+
+// Destructions returns the kinds of destruction of destructive statements, keyed by the statements.
+// Tables of dropped databases are not included, since they are dropped along with the database.
+func Destructions(alt *DatabaseAlterations) map[string]Destruction {
+	ret := map[string]Destruction{}
+	for _, d := range alt.Dropped {
+		for _, s := range d.Statements() {
+			ret[s] = DestructionDropDatabase
+		}
+	}
+
+	var tables []*TableAlterations
+	for _, d := range alt.Added {
+		tables = append(tables, d.Tables)
+	}
+	for _, d := range alt.Modified {
+		tables = append(tables, d.Tables)
+	}
+	for _, d := range alt.Retained {
+		tables = append(tables, &d.Tables)
+	}
+	for _, t := range tables {
+		for _, g := range t.statementGroups() {
+			for _, a := range g.alterations {
+				if d, ok := DestructionOf(a); ok {
+					ret[g.Statement()] = d
+				}
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDestructions(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int, c2 int);
+CREATE TABLE t2 (id int PRIMARY KEY);
+CREATE DATABASE db2;
+USE db2;
+CREATE TABLE t1 (id int PRIMARY KEY);
+`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c2 int, c3 int);
+`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	alt := NewDatabaseAlterations(from, to)
+
+	assert.Equal(t, map[string]Destruction{
+		"ALTER TABLE `db1`.`t1` DROP COLUMN `c1`, ADD COLUMN `c3` int AFTER `c2`;": DestructionDropColumn,
+		"DROP TABLE `db1`.`t2`;": DestructionDropTable,
+		"DROP DATABASE `db2`;":   DestructionDropDatabase,
+	}, Destructions(alt))
+}
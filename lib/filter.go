@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Filter selects databases, tables and columns to manage by glob patterns such as "_*_gho".
+// Databases and tables are selected if they match any include pattern (or no include pattern is given) and no
+// exclude pattern, and columns are selected if they match no exclude pattern.
+// Table patterns are either "table" or "database.table", and column patterns are "column", "table.column" or
+// "database.table.column".
+type Filter struct {
+	IncludeDatabases []string
+	ExcludeDatabases []string
+	IncludeTables    []string
+	ExcludeTables    []string
+	ExcludeColumns   []string
+}
+
+// Validate returns an error if any pattern is malformed
+func (r *Filter) Validate() error {
+	for _, patterns := range [][]string{r.IncludeDatabases, r.ExcludeDatabases, r.IncludeTables, r.ExcludeTables, r.ExcludeColumns} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern: %s : %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if the filter selects everything
+func (r *Filter) IsEmpty() bool {
+	return r == nil || len(r.IncludeDatabases)+len(r.ExcludeDatabases)+len(r.IncludeTables)+len(r.ExcludeTables)+len(r.ExcludeColumns) == 0
+}
+
+func (r *Filter) Database(dbName string) bool {
+	if r == nil {
+		return true
+	}
+	return selects(r.IncludeDatabases, r.ExcludeDatabases, dbName)
+}
+
+func (r *Filter) Table(dbName string, tableName string) bool {
+	if r == nil {
+		return true
+	}
+	return selects(r.IncludeTables, r.ExcludeTables, dbName, tableName)
+}
+
+func (r *Filter) Column(dbName string, tableName string, columnName string) bool {
+	if r == nil {
+		return true
+	}
+	return selects(nil, r.ExcludeColumns, dbName, tableName, columnName)
+}
+
+// Apply returns copies of the schemas without databases, tables and columns not selected by the filter.
+// Schemas are returned as they are if nothing is filtered out.
+func (r *Filter) Apply(schemas []*Schema) []*Schema {
+	if r.IsEmpty() {
+		return schemas
+	}
+	ret := []*Schema{}
+	for _, s := range schemas {
+		dbName := s.Database.DbName
+		if !r.Database(dbName) {
+			continue
+		}
+		tables := []*parser.CreateTableStatement{}
+		for _, t := range s.Tables {
+			if !r.Table(dbName, t.TableName) {
+				continue
+			}
+			table := *t
+			table.CreateDefinitions = RemoveIf(t.CreateDefinitions, func(e interface{}) bool {
+				return !r.definition(dbName, t.TableName, e)
+			})
+			tables = append(tables, &table)
+		}
+		ret = append(ret, &Schema{Database: s.Database, Tables: tables})
+	}
+	return ret
+}
+
+// definition returns true if the column, key or constraint of the table is selected.
+// Keys and constraints are not selected if they refer to any column not selected, since they cannot exist without it.
+func (r *Filter) definition(dbName string, tableName string, def interface{}) bool {
+	keyParts := func(keyParts []parser.KeyPart, dbName string, tableName string) bool {
+		for _, k := range keyParts {
+			if k.Column != "" && !r.Column(dbName, tableName, k.Column) {
+				return false
+			}
+			if k.Expression != "" && !r.expression(dbName, tableName, k.Expression) {
+				return false
+			}
+		}
+		return true
+	}
+	switch d := def.(type) {
+	case *parser.ColumnDefinition:
+		return r.Column(dbName, tableName, d.ColumnName)
+	case *parser.PrimaryKeyDefinition:
+		return keyParts(d.KeyPartList, dbName, tableName)
+	case *parser.UniqueKeyDefinition:
+		return keyParts(d.KeyPartList, dbName, tableName)
+	case *parser.IndexDefinition:
+		return keyParts(d.KeyPartList, dbName, tableName)
+	case *parser.FullTextIndexDefinition:
+		return keyParts(d.KeyPartList, dbName, tableName)
+	case *parser.ForeignKeyDefinition:
+		refDbName := d.ReferenceDefinition.DbName
+		if refDbName == "" {
+			refDbName = dbName
+		}
+		return keyParts(d.KeyPartList, dbName, tableName) &&
+			keyParts(d.ReferenceDefinition.KeyPartList, refDbName, d.ReferenceDefinition.TableName)
+	case *parser.CheckConstraintDefinition:
+		return r.expression(dbName, tableName, d.Check)
+	}
+	return true
+}
+
+var identifierRegexp = regexp.MustCompile("`([^`]+)`|[A-Za-z0-9_$]+")
+
+// expression returns true if the expression refers to no column not selected.
+// Every identifier is regarded as a column, which excludes the expression rather than keeps it in doubt.
+func (r *Filter) expression(dbName string, tableName string, expr string) bool {
+	for _, m := range identifierRegexp.FindAllStringSubmatch(expr, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[0]
+		}
+		if !r.Column(dbName, tableName, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// selects returns true if the qualified name matches any include pattern and no exclude pattern.
+// Each pattern is matched with as many trailing parts of the name as it has.
+func selects(includes []string, excludes []string, names ...string) bool {
+	matches := func(patterns []string) bool {
+		for _, p := range patterns {
+			n := strings.Count(p, ".") + 1
+			if n > len(names) {
+				continue
+			}
+			if ok, _ := path.Match(p, strings.Join(names[len(names)-n:], ".")); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(includes) > 0 && !matches(includes) {
+		return false
+	}
+	return !matches(excludes)
+}
@@ -0,0 +1,142 @@
+package lib
+
+import (
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/kota65535/alternator/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := &Filter{
+		IncludeDatabases: []string{"app*"},
+		ExcludeDatabases: []string{"app_test"},
+		ExcludeTables:    []string{"_*_gho", "_*_ghc", "schema_migrations", "app_analytics.*"},
+		ExcludeColumns:   []string{"users.legacy_*", "app.orders.memo"},
+	}
+	require.NoError(t, f.Validate())
+
+	assert.True(t, f.Database("app"))
+	assert.True(t, f.Database("app_analytics"))
+	assert.False(t, f.Database("app_test"))
+	assert.False(t, f.Database("other"))
+
+	assert.True(t, f.Table("app", "users"))
+	assert.False(t, f.Table("app", "_users_gho"))
+	assert.False(t, f.Table("app", "_users_ghc"))
+	assert.False(t, f.Table("app", "schema_migrations"))
+	assert.False(t, f.Table("app_analytics", "events"))
+
+	assert.True(t, f.Column("app", "users", "name"))
+	assert.False(t, f.Column("app", "users", "legacy_name"))
+	assert.False(t, f.Column("app", "orders", "memo"))
+	assert.True(t, f.Column("app2", "orders", "memo"))
+
+	assert.EqualError(t, (&Filter{ExcludeTables: []string{"[a-"}}).Validate(), "invalid pattern: [a- : syntax error in pattern")
+}
+
+func TestFilterApply(t *testing.T) {
+	schemas, err := NewSchemas(`
+CREATE DATABASE app;
+USE app;
+CREATE TABLE users (id int PRIMARY KEY, name varchar(16), legacy_name varchar(16));
+CREATE TABLE _users_gho (id int PRIMARY KEY);
+CREATE DATABASE app_test;
+`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	filtered := (&Filter{
+		ExcludeDatabases: []string{"*_test"},
+		ExcludeTables:    []string{"_*_gho"},
+		ExcludeColumns:   []string{"legacy_*"},
+	}).Apply(schemas)
+
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "app", filtered[0].Database.DbName)
+	require.Len(t, filtered[0].Tables, 1)
+	var columns []string
+	for _, c := range filtered[0].Tables[0].GetColumns() {
+		columns = append(columns, c.ColumnName)
+	}
+	assert.Equal(t, []string{"id", "name"}, columns)
+	// The original schemas are not modified
+	assert.Len(t, schemas[0].Tables[0].CreateDefinitions, 4)
+
+	assert.Same(t, schemas[0], (&Filter{}).Apply(schemas)[0])
+}
+
+func TestFilterApplyKeys(t *testing.T) {
+	schemas, err := NewSchemas(`
+CREATE DATABASE app;
+USE app;
+CREATE TABLE users (
+    id int PRIMARY KEY,
+    name varchar(16),
+    legacy_id int,
+    legacy_name varchar(16),
+    UNIQUE KEY uk1 (name, legacy_name),
+    INDEX idx1 (name),
+    INDEX idx2 (legacy_id),
+    FULLTEXT INDEX ft1 (legacy_name),
+    CONSTRAINT chk1 CHECK (legacy_id > 0),
+    CONSTRAINT chk2 CHECK (id > 0)
+);
+CREATE TABLE orders (
+    id int PRIMARY KEY,
+    user_id int,
+    legacy_user_id int,
+    CONSTRAINT fk1 FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk2 FOREIGN KEY (legacy_user_id) REFERENCES users (id),
+    CONSTRAINT fk3 FOREIGN KEY (user_id) REFERENCES users (legacy_id)
+);
+`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// Keys and constraints on the excluded columns are excluded along with them
+	filtered := (&Filter{ExcludeColumns: []string{"legacy_*"}}).Apply(schemas)
+	require.Len(t, filtered[0].Tables, 2)
+	var names []string
+	for _, d := range filtered[0].Tables[0].CreateDefinitions {
+		switch v := d.(type) {
+		case *parser.ColumnDefinition:
+			names = append(names, v.ColumnName)
+		case *parser.PrimaryKeyDefinition:
+			names = append(names, "PRIMARY")
+		case *parser.UniqueKeyDefinition:
+			names = append(names, v.IndexName)
+		case *parser.IndexDefinition:
+			names = append(names, v.IndexName)
+		case *parser.FullTextIndexDefinition:
+			names = append(names, v.IndexName)
+		case *parser.CheckConstraintDefinition:
+			names = append(names, v.ConstraintName)
+		}
+	}
+	assert.ElementsMatch(t, []string{"id", "name", "PRIMARY", "idx1", "chk2"}, names)
+
+	var foreignKeys []string
+	for _, f := range filtered[0].Tables[1].GetForeignKeys() {
+		foreignKeys = append(foreignKeys, f.ConstraintName)
+	}
+	assert.Equal(t, []string{"fk1"}, foreignKeys)
+
+	// Schema files need not declare them
+	local, err := NewSchemas(`
+CREATE DATABASE app;
+USE app;
+CREATE TABLE users (
+    id int PRIMARY KEY,
+    name varchar(16),
+    INDEX idx1 (name),
+    CONSTRAINT chk2 CHECK (id > 0)
+);
+CREATE TABLE orders (
+    id int PRIMARY KEY,
+    user_id int,
+    CONSTRAINT fk1 FOREIGN KEY (user_id) REFERENCES users (id)
+);
+`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	assert.Empty(t, NewDatabaseAlterations(filtered, local).Statements())
+}
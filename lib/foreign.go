@@ -145,7 +145,7 @@ func (r *ForeignKeyAlterations) Equivalent() bool {
 // HandleTableDrop ensures that foreign key drop is preceded to the table drop.
 func (r *ForeignKeyAlterations) HandleTableDrop(alt Alteration, tableName string) {
 	for _, d := range r.Dropped {
-		if d.This.ReferenceDefinition.TableName == tableName {
+		if referencesTable(d.This, tableName) {
 			alt.AddDependsOn(d)
 		}
 	}
@@ -156,13 +156,13 @@ func (r *ForeignKeyAlterations) HandleTableRename(alt Alteration, fromName strin
 	removed := map[Alteration]bool{}
 	// update table name of dropped foreign keys
 	for _, d := range r.Dropped {
-		if d.This.ReferenceDefinition.TableName == fromName {
+		if referencesTable(d.This, fromName) {
 			d.This.ReferenceDefinition.TableName = toName
 		}
 	}
 	// update table name of dropped foreign keys
 	for _, a := range r.Added {
-		if a.This.ReferenceDefinition.TableName == toName {
+		if referencesTable(a.This, toName) {
 			a.AddDependsOn(alt)
 		}
 	}
@@ -217,7 +217,7 @@ func (r *ForeignKeyAlterations) HandleColumnDrop(drop Alteration, columnName str
 
 func (r *ForeignKeyAlterations) HandleRefColumnDrop(drop Alteration, tableName string, columnName string) {
 	for _, f := range r.Dropped {
-		if f.This.ReferenceDefinition.TableName == tableName && keyPartContains(f.This.ReferenceDefinition.KeyPartList, columnName) {
+		if referencesTable(f.This, tableName) && keyPartContains(f.This.ReferenceDefinition.KeyPartList, columnName) {
 			drop.AddDependsOn(f)
 		}
 	}
@@ -229,7 +229,7 @@ func (r *ForeignKeyAlterations) HandleRefColumnRename(tableName string, fromName
 	// Changing key part is first considered as drop & add a foreign key, so we will find the pair
 	for _, d := range r.Dropped {
 		for _, a := range r.Added {
-			if d.This.ReferenceDefinition.TableName == tableName &&
+			if referencesTable(d.This, tableName) &&
 				keyPartContains(d.This.ReferenceDefinition.KeyPartList, fromName) &&
 				keyPartContains(a.This.ReferenceDefinition.KeyPartList, toName) {
 				// update key parts
@@ -256,7 +256,7 @@ func (r *ForeignKeyAlterations) HandleRefColumnModify(modify Alteration, tableNa
 	removed := map[Alteration]bool{}
 	// Retained foreign keys must be dropped before the column modification and added again after that
 	for _, f := range r.Retained {
-		if f.To.ReferenceDefinition.TableName == tableName && keyPartContains(f.To.ReferenceDefinition.KeyPartList, columnName) {
+		if referencesTable(f.To, tableName) && keyPartContains(f.To.ReferenceDefinition.KeyPartList, columnName) {
 			// Drop the FK before column modification
 			droppedFK := &DroppedForeignKey{
 				This:       f.From,
@@ -282,7 +282,7 @@ func (r *ForeignKeyAlterations) HandleRefColumnModify(modify Alteration, tableNa
 
 	// Dropped foreign key must be dropped before the column modification
 	for _, f := range r.Dropped {
-		if f.This.ReferenceDefinition.TableName == tableName && keyPartContains(f.This.ReferenceDefinition.KeyPartList, columnName) {
+		if referencesTable(f.This, tableName) && keyPartContains(f.This.ReferenceDefinition.KeyPartList, columnName) {
 			modify.AddDependsOn(f)
 		}
 	}
@@ -405,6 +405,11 @@ func getForeignKeyOrder(from []*parser.ForeignKeyDefinition, to []*parser.Foreig
 	return ret
 }
 
+// referencesTable returns true if the foreign key references the table in the same database
+func referencesTable(def *parser.ForeignKeyDefinition, tableName string) bool {
+	return def.ReferenceDefinition.DbName == "" && def.ReferenceDefinition.TableName == tableName
+}
+
 // Compare 2 foreign key definitions.
 // index name and constraint name of 'to' is ignored on comparison if they are empty
 func foreignKeyDefsEqual(from parser.ForeignKeyDefinition, to parser.ForeignKeyDefinition) bool {
@@ -416,5 +421,7 @@ func foreignKeyDefsEqual(from parser.ForeignKeyDefinition, to parser.ForeignKeyD
 	to.IndexName = ""
 	from.ConstraintName = ""
 	to.ConstraintName = ""
+	from.RenamedFrom = ""
+	to.RenamedFrom = ""
 	return reflect.DeepEqual(from, to) && (ti == "" || fi == ti) && (tc == "" || fc == tc)
 }
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -153,3 +154,25 @@ func TestForeignKeysWithColumnModification(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(b4), strings.Join(diffTo, "\n"))
 }
+
+func TestForeignKeysCrossDatabase(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+CREATE DATABASE db2;
+CREATE TABLE db1.t0 (id int PRIMARY KEY);
+CREATE TABLE db2.t0 (id int PRIMARY KEY);
+CREATE TABLE db1.t1 (c1 int, c2 int, FOREIGN KEY (c1) REFERENCES db1.t0 (id));`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+CREATE DATABASE db2;
+CREATE TABLE db1.t0 (id int PRIMARY KEY);
+CREATE TABLE db2.t0 (id int PRIMARY KEY);
+CREATE TABLE db1.t1 (c1 int, c2 int, FOREIGN KEY (c1) REFERENCES t0 (id), FOREIGN KEY (c2) REFERENCES db2.t0 (id));`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// The database name of the same database is omitted, while the one of other databases is kept
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` ADD FOREIGN KEY (`c2`) REFERENCES `db2`.`t0` (`id`);",
+	}, NewDatabaseAlterations(from, to).Statements())
+}
@@ -204,11 +204,14 @@ type ModifiedFullTextIndex struct {
 
 func (r ModifiedFullTextIndex) Statements() []string {
 	indexName := r.From.IndexName
+	if indexName == "" {
+		indexName = r.To.IndexName
+	}
 	if indexName == "" {
 		indexName = fmt.Sprintf("<unknown index name of '%s'>", r.From.StringKeyPartList())
 	}
 	return []string{fmt.Sprintf("ALTER INDEX `%s`%s", indexName,
-		optS(r.To.IndexOptions.Diff(r.From.IndexOptions).String(), " %s"))}
+		optS(indexOptionsDiff(r.From.IndexOptions, r.To.IndexOptions).String(), " %s"))}
 }
 
 func (r ModifiedFullTextIndex) Diff() []string {
@@ -339,5 +342,7 @@ func getFullTextIndexOrder(from []*parser.FullTextIndexDefinition, to []*parser.
 func fullTextIndexDefsEqual(c1 parser.FullTextIndexDefinition, c2 parser.FullTextIndexDefinition) bool {
 	c1.IndexName = ""
 	c2.IndexName = ""
+	c1.RenamedFrom = ""
+	c2.RenamedFrom = ""
 	return reflect.DeepEqual(c1, c2)
 }
@@ -76,6 +76,23 @@ func NewIndexAlterations(
 					Sequential: Sequential{indexOrder[s]},
 				})
 			}
+		} else if t1.IndexName != t2.IndexName && (t2.RenamedFrom == t1.IndexName || t1.RenamedFrom == t2.IndexName) {
+			// Renamed by a hint, and then modified with the new name
+			ri := &RenamedIndex{
+				From:       t1,
+				To:         t2,
+				Sequential: Sequential{indexOrder[s]},
+			}
+			from := *t1
+			from.IndexName = t2.IndexName
+			mi := &ModifiedIndex{
+				From:       &from,
+				To:         t2,
+				Sequential: Sequential{indexOrder[s]},
+			}
+			mi.AddDependsOn(ri)
+			renamed = append(renamed, ri)
+			modified = append(modified, mi)
 		} else {
 			modified = append(modified, &ModifiedIndex{
 				From:       t1,
@@ -204,11 +221,15 @@ type ModifiedIndex struct {
 
 func (r ModifiedIndex) Statements() []string {
 	indexName := r.From.IndexName
+	// The index keeps its name, which only the remote side may have on reverse alterations
+	if indexName == "" {
+		indexName = r.To.IndexName
+	}
 	if indexName == "" {
 		indexName = fmt.Sprintf("<unknown index name of '%s'>", r.From.StringKeyPartList())
 	}
 	return []string{fmt.Sprintf("ALTER INDEX `%s`%s", indexName,
-		optS(r.To.IndexOptions.Diff(r.From.IndexOptions).String(), " %s"))}
+		optS(indexOptionsDiff(r.From.IndexOptions, r.To.IndexOptions).String(), " %s"))}
 }
 
 func (r ModifiedIndex) Diff() []string {
@@ -358,3 +379,12 @@ func keyPartReplace(keyParts []parser.KeyPart, fromName string, toName string) [
 	}
 	return ret
 }
+
+// indexOptionsDiff returns the index options to alter, in which the visibility is reset to the default explicitly
+func indexOptionsDiff(from parser.IndexOptions, to parser.IndexOptions) parser.IndexOptions {
+	ret := to.Diff(from)
+	if ret.Visibility == "" && from.Visibility != "" {
+		ret.Visibility = "VISIBLE"
+	}
+	return ret
+}
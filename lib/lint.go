@@ -0,0 +1,328 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity is a severity level of lint rules
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return SeverityError, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "off", "none":
+		return SeverityOff, nil
+	}
+	return "", fmt.Errorf("unknown severity: %s", s)
+}
+
+// LintRule is a rule checking schemas as they are written in schema files.
+// A rule checks databases, tables or both.
+type LintRule struct {
+	Name        string
+	Description string
+	// Severity is the default severity, which can be overridden when linting
+	Severity      Severity
+	CheckDatabase func(d *parser.CreateDatabaseStatement) []LintFinding
+	CheckTable    func(t *parser.CreateTableStatement) []LintFinding
+}
+
+// LintFinding is a problem found by a lint rule.
+// Object is the name of a column or a constraint causing the problem, or empty if the database or table itself causes it.
+type LintFinding struct {
+	Object  string
+	Message string
+}
+
+// LintResult is a problem found by a lint rule with its location
+type LintResult struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Database string   `json:"database"`
+	Table    string   `json:"table,omitempty"`
+	Object   string   `json:"object,omitempty"`
+}
+
+var lintRules []*LintRule
+
+// RegisterLintRule adds a rule to the registry of lint rules
+func RegisterLintRule(r *LintRule) {
+	if _, ok := LintRuleOf(r.Name); ok {
+		panic(fmt.Sprintf("duplicate lint rule: %s", r.Name))
+	}
+	lintRules = append(lintRules, r)
+}
+
+// LintRules returns all registered lint rules in order of registration
+func LintRules() []*LintRule {
+	return lintRules
+}
+
+func LintRuleOf(name string) (*LintRule, bool) {
+	for _, r := range lintRules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Lint checks the schema sources by all registered rules.
+// Severities override the default severities of the rules, keyed by the rule names.
+// Rules can be disabled by comments in the sources, see scanLintSource.
+func Lint(sources []SchemaSource, severities map[string]Severity) ([]*LintResult, error) {
+	for name := range severities {
+		if _, ok := LintRuleOf(name); !ok {
+			return nil, fmt.Errorf("unknown lint rule: %s", name)
+		}
+	}
+	severityOf := func(r *LintRule) Severity {
+		if s, ok := severities[r.Name]; ok {
+			return s
+		}
+		return r.Severity
+	}
+
+	ret := []*LintResult{}
+	for _, src := range sources {
+		p := parser.NewParser(strings.NewReader(src.Body))
+		statements, err := p.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema: %s : %w", src.Name, err)
+		}
+		m := scanLintSource(src.Body)
+
+		add := func(r *LintRule, findings []LintFinding, dbName string, tableName string, l *lintLocation) {
+			for _, f := range findings {
+				loc := l
+				if o, ok := l.objects[f.Object]; ok {
+					loc = o
+				}
+				if m.disabled(r.Name) || l.disabled(r.Name) || loc.disabled(r.Name) {
+					continue
+				}
+				ret = append(ret, &LintResult{
+					Rule:     r.Name,
+					Severity: severityOf(r),
+					Message:  f.Message,
+					File:     src.Name,
+					Line:     loc.line,
+					Database: dbName,
+					Table:    tableName,
+					Object:   f.Object,
+				})
+			}
+		}
+
+		defaultDbName := ""
+		numDatabases := 0
+		numTables := 0
+		for _, s := range statements {
+			switch st := s.(type) {
+			case parser.UseStatement:
+				defaultDbName = st.DbName
+			case parser.CreateDatabaseStatement:
+				l := findLintLocation(m.databases, numDatabases, st.DbName)
+				numDatabases++
+				for _, r := range lintRules {
+					if r.CheckDatabase != nil && severityOf(r) != SeverityOff {
+						add(r, r.CheckDatabase(&st), st.DbName, "", l)
+					}
+				}
+			case parser.CreateTableStatement:
+				l := findLintLocation(m.tables, numTables, st.TableName)
+				numTables++
+				dbName := st.DbName
+				if dbName == "" {
+					dbName = defaultDbName
+				}
+				for _, r := range lintRules {
+					if r.CheckTable != nil && severityOf(r) != SeverityOff {
+						add(r, r.CheckTable(&st), dbName, st.TableName, l)
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].File != ret[j].File {
+			return ret[i].File < ret[j].File
+		}
+		return ret[i].Line < ret[j].Line
+	})
+	return ret, nil
+}
+
+// lintLocation is a location of a database, a table or a definition of a table in a schema source,
+// with the rules disabled there
+type lintLocation struct {
+	name    string
+	line    int
+	rules   map[string]bool
+	objects map[string]*lintLocation
+}
+
+func (r *lintLocation) disabled(rule string) bool {
+	return r.rules["all"] || r.rules[rule]
+}
+
+func (r *lintLocation) disable(rules map[string]bool) {
+	for k := range rules {
+		r.rules[k] = true
+	}
+}
+
+type lintSource struct {
+	lintLocation
+	databases []*lintLocation
+	tables    []*lintLocation
+}
+
+// findLintLocation returns the i-th location, or finds it by name if the scan missed some statements
+func findLintLocation(locations []*lintLocation, i int, name string) *lintLocation {
+	if i < len(locations) && locations[i].name == name {
+		return locations[i]
+	}
+	for _, l := range locations {
+		if l.name == name {
+			return l
+		}
+	}
+	return &lintLocation{name: name, line: 1, rules: map[string]bool{}, objects: map[string]*lintLocation{}}
+}
+
+const lintIdentifier = "(?:`([^`]+)`|([A-Za-z0-9_$]+))"
+
+var (
+	lintDirectiveRegexp      = regexp.MustCompile(`^alternator:lint-disable(-file)?(?:\s+(.*))?$`)
+	lintCreateDatabaseRegexp = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:DATABASE|SCHEMA)\s+(?:IF\s+NOT\s+EXISTS\s+)?` + lintIdentifier)
+	lintCreateTableRegexp    = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:` + lintIdentifier + `\s*\.\s*)?` + lintIdentifier)
+	lintNamedKeyRegexp       = regexp.MustCompile(`(?i)^(?:CONSTRAINT|(?:UNIQUE\s+|FULLTEXT\s+|SPATIAL\s+)?(?:KEY|INDEX))\s+` + lintIdentifier)
+	lintUnnamedKeyRegexp     = regexp.MustCompile(`(?i)^(?:PRIMARY|FOREIGN|CHECK|UNIQUE|FULLTEXT|SPATIAL|KEY|INDEX)\b`)
+	lintColumnRegexp         = regexp.MustCompile(`^` + lintIdentifier)
+)
+
+// scanLintSource finds the locations of databases, tables and definitions of tables in the schema source,
+// and comments disabling lint rules, which take one of the following forms:
+//
+//   - "-- alternator:lint-disable-file rule1,rule2" disables the rules in the whole source.
+//   - "-- alternator:lint-disable rule1,rule2" at the end of a line disables the rules for the database, table,
+//     column or constraint defined in the line.
+//   - "-- alternator:lint-disable rule1,rule2" in its own line disables the rules for the one defined in the next line.
+//
+// All rules are disabled if no rule is given. "#" and "/* */" comments are also available.
+func scanLintSource(body string) *lintSource {
+	ret := &lintSource{lintLocation: lintLocation{rules: map[string]bool{}}}
+	newLocation := func(name string, line int, rules map[string]bool) *lintLocation {
+		l := &lintLocation{name: name, line: line, rules: map[string]bool{}, objects: map[string]*lintLocation{}}
+		l.disable(rules)
+		return l
+	}
+
+	var table *lintLocation
+	pending := map[string]bool{}
+	for i, line := range strings.Split(body, "\n") {
+		code, comment := splitLintComment(line)
+		rules, file, ok := parseLintDirective(comment)
+		if ok && file {
+			ret.disable(rules)
+			rules = map[string]bool{}
+		}
+		if strings.TrimSpace(code) == "" {
+			for k := range rules {
+				pending[k] = true
+			}
+			continue
+		}
+		for k := range pending {
+			rules[k] = true
+		}
+		pending = map[string]bool{}
+
+		if m := lintCreateDatabaseRegexp.FindStringSubmatch(code); m != nil {
+			ret.databases = append(ret.databases, newLocation(m[1]+m[2], i+1, rules))
+		} else if m := lintCreateTableRegexp.FindStringSubmatch(code); m != nil {
+			table = newLocation(m[3]+m[4], i+1, rules)
+			ret.tables = append(ret.tables, table)
+		} else if table != nil {
+			name := lintDefinitionName(code)
+			if _, ok := table.objects[name]; name != "" && !ok {
+				table.objects[name] = newLocation(name, i+1, rules)
+			}
+		}
+		if strings.Contains(code, ";") {
+			table = nil
+		}
+	}
+	return ret
+}
+
+// splitLintComment splits a line into the code and the comment, ignoring comment markers in quotes
+func splitLintComment(line string) (string, string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#':
+			return line[:i], line[i+1:]
+		case strings.HasPrefix(line[i:], "--") || strings.HasPrefix(line[i:], "/*"):
+			return line[:i], strings.TrimSuffix(strings.TrimSpace(line[i+2:]), "*/")
+		}
+	}
+	return line, ""
+}
+
+// parseLintDirective parses a comment disabling lint rules.
+// It returns the rules and whether the rules are disabled in the whole source.
+func parseLintDirective(comment string) (map[string]bool, bool, bool) {
+	m := lintDirectiveRegexp.FindStringSubmatch(strings.TrimSpace(comment))
+	if m == nil {
+		return map[string]bool{}, false, false
+	}
+	rules := map[string]bool{}
+	for _, r := range strings.FieldsFunc(m[2], func(c rune) bool { return c == ',' || c == ' ' || c == '\t' }) {
+		rules[r] = true
+	}
+	if len(rules) == 0 {
+		rules["all"] = true
+	}
+	return rules, m[1] != "", true
+}
+
+// lintDefinitionName returns the name of the column or the constraint defined in the line of CREATE TABLE statement
+func lintDefinitionName(code string) string {
+	code = strings.TrimLeft(code, " \t,")
+	if m := lintNamedKeyRegexp.FindStringSubmatch(code); m != nil {
+		return m[1] + m[2]
+	}
+	if lintUnnamedKeyRegexp.MatchString(code) {
+		return ""
+	}
+	if m := lintColumnRegexp.FindStringSubmatch(code); m != nil {
+		return m[1] + m[2]
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	RegisterLintRule(&LintRule{
+		Name:        "primary-key",
+		Description: "Every table has a primary key",
+		Severity:    SeverityError,
+		CheckTable:  checkPrimaryKey,
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "foreign-key-index",
+		Description: "Every foreign key is backed by an index declared explicitly",
+		Severity:    SeverityWarning,
+		CheckTable:  checkForeignKeyIndex,
+	})
+	RegisterLintRule(&LintRule{
+		Name:          "no-utf8mb3",
+		Description:   "Character sets and collations do not use utf8mb3, which is deprecated",
+		Severity:      SeverityError,
+		CheckDatabase: checkNoUtf8mb3Database,
+		CheckTable:    checkNoUtf8mb3Table,
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "no-float-money",
+		Description: "Money columns do not use floating point types",
+		Severity:    SeverityError,
+		CheckTable:  checkNoFloatMoney,
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "column-comment",
+		Description: "Every column has a comment",
+		Severity:    SeverityWarning,
+		CheckTable:  checkColumnComment,
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "no-timestamp",
+		Description: "Columns do not use timestamp type, which cannot store dates after 2038-01-19",
+		Severity:    SeverityWarning,
+		CheckTable:  checkNoTimestamp,
+	})
+}
+
+func checkPrimaryKey(t *parser.CreateTableStatement) []LintFinding {
+	if len(t.GetPrimaryKeys()) > 0 {
+		return nil
+	}
+	for _, c := range t.GetColumns() {
+		if c.ColumnOptions.Primary {
+			return nil
+		}
+	}
+	return []LintFinding{{Message: fmt.Sprintf("table `%s` has no primary key", t.TableName)}}
+}
+
+func checkForeignKeyIndex(t *parser.CreateTableStatement) []LintFinding {
+	// Columns of the keys which can back foreign keys by their leftmost columns
+	var keys [][]string
+	for _, c := range t.GetColumns() {
+		if c.ColumnOptions.Primary || c.ColumnOptions.Unique {
+			keys = append(keys, []string{c.ColumnName})
+		}
+	}
+	for _, k := range t.GetPrimaryKeys() {
+		keys = append(keys, keyPartColumns(k.KeyPartList))
+	}
+	for _, k := range t.GetUniqueKeys() {
+		keys = append(keys, keyPartColumns(k.KeyPartList))
+	}
+	for _, k := range t.GetIndexes() {
+		keys = append(keys, keyPartColumns(k.KeyPartList))
+	}
+	backed := func(columns []string) bool {
+		for _, k := range keys {
+			if len(k) >= len(columns) && arraysEqual(k[:len(columns)], columns) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ret []LintFinding
+	for _, c := range t.GetColumns() {
+		if c.ColumnOptions.ReferenceDefinition.TableName != "" && !backed([]string{c.ColumnName}) {
+			ret = append(ret, LintFinding{
+				Object:  c.ColumnName,
+				Message: fmt.Sprintf("foreign key of column `%s` is not backed by an index", c.ColumnName),
+			})
+		}
+	}
+	for _, f := range t.GetForeignKeys() {
+		columns := keyPartColumns(f.KeyPartList)
+		if backed(columns) {
+			continue
+		}
+		object := f.ConstraintName
+		if object == "" {
+			object = columns[0]
+		}
+		ret = append(ret, LintFinding{
+			Object:  object,
+			Message: fmt.Sprintf("foreign key (%s) is not backed by an index", parser.JoinS(columns, ", ", "`")),
+		})
+	}
+	return ret
+}
+
+func keyPartColumns(keyParts []parser.KeyPart) []string {
+	var ret []string
+	for _, k := range keyParts {
+		ret = append(ret, k.Column)
+	}
+	return ret
+}
+
+func isUtf8mb3(charsetOrCollation string) bool {
+	s := strings.ToLower(charsetOrCollation)
+	return s == "utf8" || s == "utf8mb3" || strings.HasPrefix(s, "utf8_") || strings.HasPrefix(s, "utf8mb3_")
+}
+
+func checkNoUtf8mb3Database(d *parser.CreateDatabaseStatement) []LintFinding {
+	o := d.DatabaseOptions
+	if isUtf8mb3(o.DefaultCharset) || isUtf8mb3(o.DefaultCollate) {
+		return []LintFinding{{Message: fmt.Sprintf("database `%s` uses utf8mb3. Use utf8mb4 instead", d.DbName)}}
+	}
+	return nil
+}
+
+func checkNoUtf8mb3Table(t *parser.CreateTableStatement) []LintFinding {
+	var ret []LintFinding
+	o := t.TableOptions
+	if isUtf8mb3(o.DefaultCharset) || isUtf8mb3(o.DefaultCollate) {
+		ret = append(ret, LintFinding{Message: fmt.Sprintf("table `%s` uses utf8mb3. Use utf8mb4 instead", t.TableName)})
+	}
+	for _, c := range t.GetColumns() {
+		var charset, collation string
+		switch dt := c.DataType.(type) {
+		case parser.StringType:
+			charset, collation = dt.Charset, dt.Collation
+		case parser.StringListType:
+			charset, collation = dt.Charset, dt.Collation
+		}
+		if isUtf8mb3(charset) || isUtf8mb3(collation) {
+			ret = append(ret, LintFinding{
+				Object:  c.ColumnName,
+				Message: fmt.Sprintf("column `%s` uses utf8mb3. Use utf8mb4 instead", c.ColumnName),
+			})
+		}
+	}
+	return ret
+}
+
+// moneyColumnRegexp matches names of columns which are likely to store money
+var moneyColumnRegexp = regexp.MustCompile(`(?i)(^|_)(price|amount|cost|fee|total|balance|salary|tax|payment|money)s?($|_)`)
+
+func checkNoFloatMoney(t *parser.CreateTableStatement) []LintFinding {
+	var ret []LintFinding
+	for _, c := range t.GetColumns() {
+		if dt, ok := c.DataType.(parser.FloatingPointType); ok && moneyColumnRegexp.MatchString(c.ColumnName) {
+			ret = append(ret, LintFinding{
+				Object:  c.ColumnName,
+				Message: fmt.Sprintf("column `%s` seems to store money in %s type. Use decimal type instead", c.ColumnName, dt.Name),
+			})
+		}
+	}
+	return ret
+}
+
+func checkColumnComment(t *parser.CreateTableStatement) []LintFinding {
+	var ret []LintFinding
+	for _, c := range t.GetColumns() {
+		if c.ColumnOptions.Comment == "" {
+			ret = append(ret, LintFinding{
+				Object:  c.ColumnName,
+				Message: fmt.Sprintf("column `%s` has no comment", c.ColumnName),
+			})
+		}
+	}
+	return ret
+}
+
+func checkNoTimestamp(t *parser.CreateTableStatement) []LintFinding {
+	var ret []LintFinding
+	for _, c := range t.GetColumns() {
+		if dt, ok := c.DataType.(parser.DateAndTimeType); ok && strings.EqualFold(dt.Name, "timestamp") {
+			ret = append(ret, LintFinding{
+				Object:  c.ColumnName,
+				Message: fmt.Sprintf("column `%s` uses timestamp type, which cannot store dates after 2038-01-19. Use datetime type instead", c.ColumnName),
+			})
+		}
+	}
+	return ret
+}
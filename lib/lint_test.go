@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	sources := []SchemaSource{{Name: "schema.sql", Body: `CREATE DATABASE db1 DEFAULT CHARACTER SET utf8;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY COMMENT 'ID',
+    name varchar(16) CHARACTER SET utf8mb3 COMMENT 'Name',
+    price float COMMENT 'Price',
+    created_at timestamp COMMENT 'Created time'
+);
+CREATE TABLE t2 (
+    t1_id int COMMENT 'ID of t1',
+    t1_name varchar(16),
+    CONSTRAINT fk1 FOREIGN KEY (t1_id) REFERENCES t1 (id)
+);
+CREATE TABLE t3 (
+    id int COMMENT 'ID',
+    t2_id int COMMENT 'ID of t2',
+    PRIMARY KEY (id),
+    INDEX idx1 (t2_id, id),
+    FOREIGN KEY (t2_id) REFERENCES t2 (t1_id)
+);
+`}}
+	results, err := Lint(sources, map[string]Severity{"no-timestamp": SeverityError})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*LintResult{
+		{Rule: "no-utf8mb3", Severity: SeverityError, Message: "database `db1` uses utf8mb3. Use utf8mb4 instead", File: "schema.sql", Line: 1, Database: "db1"},
+		{Rule: "no-utf8mb3", Severity: SeverityError, Message: "column `name` uses utf8mb3. Use utf8mb4 instead", File: "schema.sql", Line: 5, Database: "db1", Table: "t1", Object: "name"},
+		{Rule: "no-float-money", Severity: SeverityError, Message: "column `price` seems to store money in float type. Use decimal type instead", File: "schema.sql", Line: 6, Database: "db1", Table: "t1", Object: "price"},
+		{Rule: "no-timestamp", Severity: SeverityError, Message: "column `created_at` uses timestamp type, which cannot store dates after 2038-01-19. Use datetime type instead", File: "schema.sql", Line: 7, Database: "db1", Table: "t1", Object: "created_at"},
+		{Rule: "primary-key", Severity: SeverityError, Message: "table `t2` has no primary key", File: "schema.sql", Line: 9, Database: "db1", Table: "t2"},
+		{Rule: "column-comment", Severity: SeverityWarning, Message: "column `t1_name` has no comment", File: "schema.sql", Line: 11, Database: "db1", Table: "t2", Object: "t1_name"},
+		{Rule: "foreign-key-index", Severity: SeverityWarning, Message: "foreign key (`t1_id`) is not backed by an index", File: "schema.sql", Line: 12, Database: "db1", Table: "t2", Object: "fk1"},
+	}, results)
+}
+
+func TestLintDisabled(t *testing.T) {
+	sources := []SchemaSource{{Name: "schema.sql", Body: `-- alternator:lint-disable-file no-timestamp
+CREATE DATABASE db1;
+USE db1;
+-- alternator:lint-disable primary-key
+CREATE TABLE t1 (
+    id int, # alternator:lint-disable column-comment
+    price float, -- alternator:lint-disable no-float-money, column-comment
+    # alternator:lint-disable
+    name varchar(16) CHARACTER SET utf8,
+    created_at timestamp COMMENT 'Created time',
+    updated_at datetime /* alternator:lint-disable column-comment */
+);
+CREATE TABLE t2 (
+    id int PRIMARY KEY COMMENT 'ID',
+    total_price double COMMENT '-- alternator:lint-disable'
+);
+`}}
+	results, err := Lint(sources, map[string]Severity{"no-float-money": SeverityOff})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*LintResult{}, results)
+
+	results, err = Lint(sources, map[string]Severity{"column-comment": SeverityError})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*LintResult{
+		{Rule: "no-float-money", Severity: SeverityError, Message: "column `total_price` seems to store money in double type. Use decimal type instead", File: "schema.sql", Line: 15, Database: "db1", Table: "t2", Object: "total_price"},
+	}, results)
+}
+
+func TestLintUnknownRule(t *testing.T) {
+	_, err := Lint(nil, map[string]Severity{"no-such-rule": SeverityOff})
+	assert.EqualError(t, err, "unknown lint rule: no-such-rule")
+}
@@ -0,0 +1,314 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultRenameThreshold never renames by similarity, since thresholds of 1 or more disable it
+	DefaultRenameThreshold = 1.0
+	// minRenameCandidateScore is the minimum score of the pairs reported as rename candidates
+	minRenameCandidateScore = 0.5
+)
+
+// RenameCandidate is a pair of dropped and added table or column, which may have been renamed.
+// Score is the confidence from 0 to 1 rounded to two decimals, and the pair is renamed instead of dropped and added if
+// the unrounded score is at least the rename threshold below 1.
+type RenameCandidate struct {
+	Type     string  `json:"type"`
+	Database string  `json:"database"`
+	Table    string  `json:"table,omitempty"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Score    float64 `json:"score"`
+	Renamed  bool    `json:"renamed"`
+}
+
+func (r RenameCandidate) String() string {
+	prefix := fmt.Sprintf("`%s`.", r.Database)
+	if r.Table != "" {
+		prefix += fmt.Sprintf("`%s`.", r.Table)
+	}
+	return fmt.Sprintf("%s %s`%s` -> `%s`", r.Type, prefix, r.From, r.To)
+}
+
+// tableRenameCandidates pairs the dropped and added tables by their similarity
+func tableRenameCandidates(from []*parser.CreateTableStatement, to []*parser.CreateTableStatement, threshold float64) []*RenameCandidate {
+	var ret []*RenameCandidate
+	for _, c := range matchRenameCandidates(len(from), len(to), func(i, j int) float64 {
+		return tableSimilarity(from[i], to[j])
+	}) {
+		ret = append(ret, &RenameCandidate{
+			Type:     "table",
+			Database: to[c.to].DbName,
+			From:     from[c.from].TableName,
+			To:       to[c.to].TableName,
+			Score:    round(c.score),
+			Renamed:  renamedBySimilarity(c.score, threshold),
+		})
+	}
+	return ret
+}
+
+// columnRenameCandidates pairs the columns existing only in either table by their similarity.
+// Columns with rename hints are left to the hints.
+func columnRenameCandidates(t1 *parser.CreateTableStatement, t2 *parser.CreateTableStatement, threshold float64) []*RenameCandidate {
+	columns1 := t1.GetColumns()
+	columns2 := t2.GetColumns()
+	names1 := map[string]bool{}
+	names2 := map[string]bool{}
+	hinted := map[string]bool{}
+	for _, c := range columns1 {
+		names1[c.ColumnName] = true
+		hinted[c.RenamedFrom] = true
+	}
+	for _, c := range columns2 {
+		names2[c.ColumnName] = true
+		hinted[c.RenamedFrom] = true
+	}
+	var from, to []int
+	for i, c := range columns1 {
+		if !names2[c.ColumnName] && !hinted[c.ColumnName] && c.RenamedFrom == "" {
+			from = append(from, i)
+		}
+	}
+	for j, c := range columns2 {
+		if !names1[c.ColumnName] && !hinted[c.ColumnName] && c.RenamedFrom == "" {
+			to = append(to, j)
+		}
+	}
+
+	keys1 := columnKeys(t1)
+	keys2 := columnKeys(t2)
+	var ret []*RenameCandidate
+	for _, c := range matchRenameCandidates(len(from), len(to), func(i, j int) float64 {
+		return columnSimilarity(columns1, columns2, from[i], to[j], keys1, keys2)
+	}) {
+		ret = append(ret, &RenameCandidate{
+			Type:     "column",
+			Database: t2.DbName,
+			Table:    t2.TableName,
+			From:     columns1[from[c.from]].ColumnName,
+			To:       columns2[to[c.to]].ColumnName,
+			Score:    round(c.score),
+			Renamed:  renamedBySimilarity(c.score, threshold),
+		})
+	}
+	return ret
+}
+
+// renamedBySimilarity returns true if the score is at least the threshold.
+// Thresholds of 1 or more disable renaming by similarity, since names differing only by letter case score 1.
+func renamedBySimilarity(score float64, threshold float64) bool {
+	return threshold < 1 && score >= threshold
+}
+
+type renamePair struct {
+	from  int
+	to    int
+	score float64
+}
+
+// matchRenameCandidates pairs the indices of 'from' and 'to' greedily in descending order of their scores.
+// Ties are broken by the order of 'from' and then 'to'.
+func matchRenameCandidates(n1 int, n2 int, score func(int, int) float64) []renamePair {
+	var pairs []renamePair
+	for i := 0; i < n1; i++ {
+		for j := 0; j < n2; j++ {
+			if s := score(i, j); s >= minRenameCandidateScore {
+				pairs = append(pairs, renamePair{i, j, s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		return pairs[a].score > pairs[b].score
+	})
+	usedFrom := map[int]bool{}
+	usedTo := map[int]bool{}
+	var ret []renamePair
+	for _, p := range pairs {
+		if usedFrom[p.from] || usedTo[p.to] {
+			continue
+		}
+		ret = append(ret, p)
+		usedFrom[p.from] = true
+		usedTo[p.to] = true
+	}
+	sort.SliceStable(ret, func(a, b int) bool {
+		return ret[a].to < ret[b].to
+	})
+	return ret
+}
+
+// tableSimilarity scores how likely the table has been renamed, by the similarity of their names, the types of the
+// columns at the same positions, and the key parts of the indexes
+func tableSimilarity(t1 *parser.CreateTableStatement, t2 *parser.CreateTableStatement) float64 {
+	columns1 := t1.GetColumns()
+	columns2 := t2.GetColumns()
+	n := max(len(columns1), len(columns2))
+	columns := 1.0
+	if n > 0 {
+		sum := 0.0
+		for i := 0; i < min(len(columns1), len(columns2)); i++ {
+			sum += (typeSimilarity(columns1[i], columns2[i]) + nameSimilarity(columns1[i].ColumnName, columns2[i].ColumnName)) / 2
+		}
+		columns = sum / float64(n)
+	}
+	keys := jaccard(tableKeys(t1), tableKeys(t2))
+	return 0.3*nameSimilarity(t1.TableName, t2.TableName) + 0.5*columns + 0.2*keys
+}
+
+// columnSimilarity scores how likely the column has been renamed, by the similarity of their names, the type family,
+// the position, and the key parts of the indexes containing them
+func columnSimilarity(columns1 []*parser.ColumnDefinition, columns2 []*parser.ColumnDefinition, i int, j int,
+	keys1 map[string]map[string]bool, keys2 map[string]map[string]bool) float64 {
+	c1 := columns1[i]
+	c2 := columns2[j]
+	position := 0.0
+	if i == j || (i > 0 && j > 0 && columns1[i-1].ColumnName == columns2[j-1].ColumnName) {
+		position = 1
+	}
+	keys := jaccard(keys1[c1.ColumnName], keys2[c2.ColumnName])
+	return 0.35*typeSimilarity(c1, c2) + 0.35*nameSimilarity(c1.ColumnName, c2.ColumnName) + 0.15*position + 0.15*keys
+}
+
+// typeSimilarity returns 1 if the data types are the same, 0.5 if they are of the same family such as integer types,
+// and 0 otherwise
+func typeSimilarity(c1 *parser.ColumnDefinition, c2 *parser.ColumnDefinition) float64 {
+	if c1.EqualsExceptColumnName(*c2) || fmt.Sprint(c1.DataType) == fmt.Sprint(c2.DataType) {
+		return 1
+	}
+	if reflect.TypeOf(c1.DataType) == reflect.TypeOf(c2.DataType) {
+		return 0.5
+	}
+	return 0
+}
+
+// nameSimilarity returns the similarity of the names from 0 to 1 by their edit distance
+func nameSimilarity(s1 string, s2 string) float64 {
+	r1 := []rune(strings.ToLower(s1))
+	r2 := []rune(strings.ToLower(s2))
+	n := max(len(r1), len(r2))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(r1, r2))/float64(n)
+}
+
+func levenshtein(r1 []rune, r2 []rune) int {
+	prev := make([]int, len(r2)+1)
+	cur := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		cur[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(r2)]
+}
+
+// keyPartLists returns the column names of the key parts of the indexes and the constraints, prefixed by their kinds
+func keyPartLists(t *parser.CreateTableStatement) map[string][][]string {
+	ret := map[string][][]string{}
+	add := func(kind string, keyParts []parser.KeyPart) {
+		var names []string
+		for _, k := range keyParts {
+			names = append(names, k.Column)
+		}
+		ret[kind] = append(ret[kind], names)
+	}
+	for _, d := range t.GetPrimaryKeys() {
+		add("PRIMARY", d.KeyPartList)
+	}
+	for _, d := range t.GetUniqueKeys() {
+		add("UNIQUE", d.KeyPartList)
+	}
+	for _, d := range t.GetIndexes() {
+		add("INDEX", d.KeyPartList)
+	}
+	for _, d := range t.GetFullTextIndexes() {
+		add("FULLTEXT", d.KeyPartList)
+	}
+	for _, d := range t.GetForeignKeys() {
+		add("FOREIGN", d.KeyPartList)
+	}
+	return ret
+}
+
+// tableKeys returns the key part lists by the positions of the columns, so that they are compared regardless of
+// column renames
+func tableKeys(t *parser.CreateTableStatement) map[string]bool {
+	positions := map[string]int{}
+	for i, c := range t.GetColumns() {
+		positions[c.ColumnName] = i
+	}
+	ret := map[string]bool{}
+	for kind, lists := range keyPartLists(t) {
+		for _, names := range lists {
+			var parts []string
+			for _, n := range names {
+				if p, ok := positions[n]; ok {
+					parts = append(parts, fmt.Sprint(p))
+				} else {
+					parts = append(parts, "?")
+				}
+			}
+			ret[fmt.Sprintf("%s(%s)", kind, strings.Join(parts, ","))] = true
+		}
+	}
+	return ret
+}
+
+// columnKeys returns the key part lists containing each column, in which the column itself is replaced by "?" so
+// that they are compared regardless of its rename
+func columnKeys(t *parser.CreateTableStatement) map[string]map[string]bool {
+	ret := map[string]map[string]bool{}
+	for kind, lists := range keyPartLists(t) {
+		for _, names := range lists {
+			for i, n := range names {
+				parts := append([]string{}, names...)
+				parts[i] = "?"
+				if ret[n] == nil {
+					ret[n] = map[string]bool{}
+				}
+				ret[n][fmt.Sprintf("%s(%s)", kind, strings.Join(parts, ","))] = true
+			}
+		}
+	}
+	return ret
+}
+
+// jaccard returns the Jaccard index of the sets, which is 1 if both are empty
+func jaccard(s1 map[string]bool, s2 map[string]bool) float64 {
+	union := map[string]bool{}
+	common := 0
+	for k := range s1 {
+		union[k] = true
+		if s2[k] {
+			common++
+		}
+	}
+	for k := range s2 {
+		union[k] = true
+	}
+	if len(union) == 0 {
+		return 1
+	}
+	return float64(common) / float64(len(union))
+}
+
+func round(f float64) float64 {
+	return float64(int(f*100+0.5)) / 100
+}
@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, nameSimilarity("email", "EMAIL"))
+	assert.Equal(t, 0.8, nameSimilarity("email", "mail"))
+	assert.Equal(t, 0.0, nameSimilarity("abc", "xyz"))
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+}
+
+func TestRenameCandidates(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE users (
+    id int PRIMARY KEY,
+    name varchar(100),
+    email varchar(255),
+    INDEX idx_email (email)
+);
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    title varchar(100),
+    created datetime
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE members (
+    id int PRIMARY KEY,
+    name varchar(100),
+    mail varchar(255),
+    INDEX idx_email (mail)
+);
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    subject varchar(200),
+    created_at datetime(6),
+    body text
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// By default, candidates are only reported and dropped and added
+	alt := NewDatabaseAlterations(from, to)
+	statements := alt.Statements()
+	require.Len(t, statements, 3)
+	assert.Equal(t, "DROP TABLE `db1`.`users`;", statements[1])
+	assert.Equal(t, []RenameCandidate{
+		{Type: "table", Database: "db1", From: "users", To: "members", Score: 0.81},
+		{Type: "column", Database: "db1", Table: "t1", From: "title", To: "subject", Score: 0.53},
+		{Type: "column", Database: "db1", Table: "t1", From: "created", To: "created_at", Score: 0.72},
+	}, derefCandidates(alt.RenameCandidates()))
+
+	// Candidates scored at least the threshold are renamed along with their modifications
+	alt = NewDatabaseAlterationsWithRenameThreshold(from, to, 0.7)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`users` RENAME TO `db1`.`members`;",
+		"ALTER TABLE `db1`.`members` CHANGE COLUMN `email` `mail` varchar(255);",
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `subject` varchar(200) AFTER `id`, DROP COLUMN `title`, " +
+			"CHANGE COLUMN `created` `created_at` datetime(6), ADD COLUMN `body` text AFTER `created_at`;",
+	}, alt.Statements())
+	assert.Equal(t, []RenameCandidate{
+		{Type: "table", Database: "db1", From: "users", To: "members", Score: 0.81, Renamed: true},
+		{Type: "column", Database: "db1", Table: "t1", From: "title", To: "subject", Score: 0.53},
+		{Type: "column", Database: "db1", Table: "t1", From: "created", To: "created_at", Score: 0.72, Renamed: true},
+		{Type: "column", Database: "db1", Table: "members", From: "email", To: "mail", Score: 0.93, Renamed: true},
+	}, derefCandidates(alt.RenameCandidates()))
+
+	// The threshold is kept on reverting
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`members` RENAME TO `db1`.`users`;",
+		"ALTER TABLE `db1`.`users` CHANGE COLUMN `mail` `email` varchar(255);",
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `title` varchar(100) AFTER `id`, DROP COLUMN `subject`, " +
+			"CHANGE COLUMN `created_at` `created` datetime, DROP COLUMN `body`;",
+	}, alt.Reverse().Statements())
+}
+
+func derefCandidates(candidates []*RenameCandidate) []RenameCandidate {
+	ret := []RenameCandidate{}
+	for _, c := range candidates {
+		ret = append(ret, *c)
+	}
+	return ret
+}
+
+func TestRenameThresholdDisabled(t *testing.T) {
+	// The names differ by one letter, so that the score is rounded to 1
+	name := strings.Repeat("a", 60)
+	from, err := NewSchemas(fmt.Sprintf(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE %s1 (id int PRIMARY KEY, name varchar(100));`, name), TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(fmt.Sprintf(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE %s2 (id int PRIMARY KEY, name varchar(100) NOT NULL);`, name), TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	alt := NewDatabaseAlterations(from, to)
+	candidates := alt.RenameCandidates()
+	require.Len(t, candidates, 1)
+	assert.Equal(t, 1.0, candidates[0].Score)
+	assert.False(t, candidates[0].Renamed)
+	assert.Len(t, alt.Statements(), 2)
+
+	// The unrounded score is compared with the threshold
+	alt = NewDatabaseAlterationsWithRenameThreshold(from, to, 0.999)
+	assert.False(t, alt.RenameCandidates()[0].Renamed)
+	alt = NewDatabaseAlterationsWithRenameThreshold(from, to, 0.99)
+	assert.True(t, alt.RenameCandidates()[0].Renamed)
+}
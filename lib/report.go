@@ -0,0 +1,284 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/kota65535/alternator/parser"
+	"reflect"
+	"strings"
+)
+
+// Report is a structured representation of database alterations, which is supposed to be serialized as
+// machine-readable output.
+type Report struct {
+	Databases []*AlterationReport `json:"databases"`
+	// Statements are all statements to execute in order
+	Statements []string `json:"statements"`
+	// ReverseStatements are all statements to revert the alterations in order
+	ReverseStatements []string `json:"reverse_statements,omitempty"`
+	// RenameCandidates are the pairs of dropped and added tables or columns which may have been renamed
+	RenameCandidates []*RenameCandidate `json:"rename_candidates,omitempty"`
+}
+
+// AlterationReport is a structured representation of an alteration and its child alterations.
+type AlterationReport struct {
+	Kind         Kind                `json:"kind"`
+	Type         string              `json:"type"`
+	Name         string              `json:"name"`
+	From         string              `json:"from,omitempty"`
+	To           string              `json:"to,omitempty"`
+	Irreversible bool                `json:"irreversible,omitempty"`
+	NoReverse    bool                `json:"no_reverse,omitempty"`
+	Destructive  bool                `json:"destructive,omitempty"`
+	Algorithm    Algorithm           `json:"algorithm,omitempty"`
+	Statements   []string            `json:"statements,omitempty"`
+	Children     []*AlterationReport `json:"children,omitempty"`
+}
+
+func NewReport(alt *DatabaseAlterations) *Report {
+	databases := []*AlterationReport{}
+	for _, a := range alt.Alterations() {
+		switch v := a.(type) {
+		case *AddedDatabase:
+			databases = append(databases, newAlterationReport(v, []string{v.This.String()}, tableReports(v.Tables, true)))
+		case *ModifiedDatabase:
+			databases = append(databases, newAlterationReport(v, v.optionStatements(), tableReports(v.Tables, true)))
+		case *DroppedDatabase:
+			// Tables are dropped along with the database, so their statements are not executed
+			databases = append(databases, newAlterationReport(v, v.Statements(), tableReports(v.Tables, false)))
+		case *RetainedDatabase:
+			children := tableReports(&v.Tables, true)
+			if len(children) > 0 {
+				databases = append(databases, newAlterationReport(v, nil, children))
+			}
+		}
+	}
+	return &Report{
+		Databases:        databases,
+		Statements:       alt.Statements(),
+		RenameCandidates: alt.RenameCandidates(),
+	}
+}
+
+// SetAlgorithms sets the online DDL algorithms of the alterations executed by ALTER TABLE statements
+func (r *Report) SetAlgorithms(ddls map[string]OnlineDdl) {
+	for _, d := range r.Databases {
+		d.setAlgorithms(ddls)
+	}
+}
+
+func (r *AlterationReport) setAlgorithms(ddls map[string]OnlineDdl) {
+	if len(r.Statements) > 0 {
+		if ddl, ok := ddls[r.Statements[0]]; ok {
+			r.Algorithm = ddl.Algorithm
+		}
+	}
+	for _, c := range r.Children {
+		c.setAlgorithms(ddls)
+	}
+}
+
+// HasKind returns true if the report contains any alteration of the given kinds.
+// Only alterations executed by their own statements are regarded, so that tables and databases containing the
+// alterations do not match by their kinds, e.g. a table only with added columns is not regarded as modified.
+func (r *Report) HasKind(kinds ...Kind) bool {
+	for _, d := range r.Databases {
+		if d.hasKind(kinds) {
+			return true
+		}
+	}
+	return false
+}
+
+// Irreversible returns the reports of irreversible alterations.
+// Children of an irreversible alteration are not included, since they are lost along with the parent.
+func (r *Report) Irreversible() []*AlterationReport {
+	ret := []*AlterationReport{}
+	for _, d := range r.Databases {
+		ret = append(ret, d.irreversible()...)
+	}
+	return ret
+}
+
+// WithoutReverse returns the reports of alterations whose reverse alterations cannot be built.
+func (r *Report) WithoutReverse() []*AlterationReport {
+	ret := []*AlterationReport{}
+	for _, d := range r.Databases {
+		ret = append(ret, d.find(func(a *AlterationReport) bool { return a.NoReverse })...)
+	}
+	return ret
+}
+
+// Destructive returns the reports of destructive alterations, which drop objects along with their data.
+// Children of a destructive alteration are not included, since they are dropped along with the parent.
+func (r *Report) Destructive() []*AlterationReport {
+	ret := []*AlterationReport{}
+	for _, d := range r.Databases {
+		ret = append(ret, d.find(func(a *AlterationReport) bool { return a.Destructive })...)
+	}
+	return ret
+}
+
+func (r *AlterationReport) irreversible() []*AlterationReport {
+	return r.find(func(a *AlterationReport) bool { return a.Irreversible })
+}
+
+// find returns the outermost reports satisfying the condition
+func (r *AlterationReport) find(f func(*AlterationReport) bool) []*AlterationReport {
+	if f(r) {
+		return []*AlterationReport{r}
+	}
+	ret := []*AlterationReport{}
+	for _, c := range r.Children {
+		ret = append(ret, c.find(f)...)
+	}
+	return ret
+}
+
+func (r *AlterationReport) hasKind(kinds []Kind) bool {
+	for _, k := range kinds {
+		if r.Kind == k && len(r.Statements) > 0 {
+			return true
+		}
+	}
+	for _, c := range r.Children {
+		if c.hasKind(kinds) {
+			return true
+		}
+	}
+	return false
+}
+
+func tableReports(tables *TableAlterations, withStatements bool) []*AlterationReport {
+	ret := []*AlterationReport{}
+	for _, a := range tables.Alterations() {
+		var report *AlterationReport
+		switch v := a.(type) {
+		case *AddedTable, *DroppedTable:
+			report = newAlterationReport(v, completeStatements(v, ""), nil)
+		case *ModifiedTable:
+			report = newAlterationReport(v, nil, elementReports(v.Alterations(), tablePrefix(v.To)))
+		case *RenamedTable:
+			// The rest of statements are of foreign keys, which are reported as children
+			statements := completeStatements(v, "")[:1]
+			children := elementReports(v.ForeignKeys.Alterations(), tablePrefix(v.To))
+			if v.Modified != nil {
+				children = append(children, elementReports(v.Modified.Alterations(), tablePrefix(v.To))...)
+			}
+			report = newAlterationReport(v, statements, children)
+		case *RetainedTable:
+			children := elementReports(v.ForeignKeys.Alterations(), tablePrefix(v.This))
+			if len(children) > 0 {
+				report = newAlterationReport(v, nil, children)
+			}
+		}
+		if report == nil {
+			continue
+		}
+		if !withStatements {
+			clearStatements(report)
+		}
+		ret = append(ret, report)
+	}
+	return ret
+}
+
+func elementReports(alterations []Alteration, prefix string) []*AlterationReport {
+	ret := []*AlterationReport{}
+	for _, a := range alterations {
+		if KindOf(a) == KindRetained {
+			continue
+		}
+		ret = append(ret, newAlterationReport(a, completeStatements(a, prefix), nil))
+	}
+	return ret
+}
+
+func newAlterationReport(a Alteration, statements []string, children []*AlterationReport) *AlterationReport {
+	typ, name := objectOf(a)
+	_, destructive := DestructionOf(a)
+	from := definitionString(a.FromString())
+	to := definitionString(a.ToString())
+	switch a.(type) {
+	case *DatabaseOptionAlterations, *TableOptionAlterations:
+		from = strings.Join(a.FromString(), " ")
+		to = strings.Join(a.ToString(), " ")
+	}
+	return &AlterationReport{
+		Kind:         KindOf(a),
+		Type:         typ,
+		Name:         name,
+		From:         from,
+		To:           to,
+		Irreversible: IsIrreversible(a),
+		NoReverse:    !HasReverse(a),
+		Destructive:  destructive,
+		Statements:   statements,
+		Children:     children,
+	}
+}
+
+func clearStatements(report *AlterationReport) {
+	report.Statements = nil
+	for _, c := range report.Children {
+		clearStatements(c)
+	}
+}
+
+// objectOf returns type and name of the object to be altered
+func objectOf(a Alteration) (string, string) {
+	v := reflect.Indirect(reflect.ValueOf(a))
+	var def interface{}
+	for _, n := range []string{"To", "This", "From"} {
+		if f := v.FieldByName(n); f.IsValid() && f.Kind() == reflect.Pointer && !f.IsNil() {
+			def = f.Interface()
+			break
+		}
+	}
+	switch d := def.(type) {
+	case *parser.CreateDatabaseStatement:
+		return "database", d.DbName
+	case *parser.DatabaseOptions:
+		return "database_options", ""
+	case *parser.CreateTableStatement:
+		return "table", d.TableName
+	case *parser.TableOptions:
+		return "table_options", ""
+	case *parser.ColumnDefinition:
+		return "column", d.ColumnName
+	case *parser.PrimaryKeyDefinition:
+		return "primary_key", "PRIMARY"
+	case *parser.UniqueKeyDefinition:
+		return "unique_key", nameOrKeyParts(d.IndexName, d.KeyPartList)
+	case *parser.IndexDefinition:
+		return "index", nameOrKeyParts(d.IndexName, d.KeyPartList)
+	case *parser.FullTextIndexDefinition:
+		return "fulltext_index", nameOrKeyParts(d.IndexName, d.KeyPartList)
+	case *parser.ForeignKeyDefinition:
+		return "foreign_key", nameOrKeyParts(d.ConstraintName, d.KeyPartList)
+	case *parser.CheckConstraintDefinition:
+		if d.ConstraintName != "" {
+			return "check_constraint", d.ConstraintName
+		}
+		return "check_constraint", d.Check
+	}
+	return "", ""
+}
+
+func nameOrKeyParts(name string, keyParts []parser.KeyPart) string {
+	if name != "" {
+		return name
+	}
+	return parser.JoinT(keyParts, ", ", "")
+}
+
+// definitionString returns the first definition of the strings, which is of the altered object itself
+func definitionString(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	return strings.Join(parser.Align(strs[:1]), "")
+}
+
+func tablePrefix(t *parser.CreateTableStatement) string {
+	return fmt.Sprintf("ALTER TABLE `%s`.`%s` ", t.DbName, t.TableName)
+}
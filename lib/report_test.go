@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"encoding/json"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	alt := getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")
+	report := NewReport(alt)
+
+	b1, err := json.MarshalIndent(report, "", "  ")
+	require.NoError(t, err)
+
+	b2, err := os.ReadFile("test/db/report.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(b2), string(b1))
+	assert.Equal(t, alt.Statements(), report.Statements)
+}
+
+func TestReportHasKind(t *testing.T) {
+	alt := getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")
+	report := NewReport(alt)
+
+	assert.True(t, report.HasKind(KindDropped))
+	assert.True(t, report.HasKind(KindMoved, KindRenamed))
+	assert.False(t, report.HasKind(KindMoved))
+	assert.False(t, report.HasKind())
+}
+
+func TestReportHasKindOfContainers(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (id int PRIMARY KEY, c1 int, INDEX idx1 (c1));`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// The table only with added children is not modified by itself
+	report := NewReport(NewDatabaseAlterations(from, to))
+	require.Equal(t, KindModified, report.Databases[0].Children[0].Kind)
+	assert.True(t, report.HasKind(KindAdded))
+	assert.False(t, report.HasKind(KindModified))
+	assert.False(t, report.HasKind(KindRetained))
+}
+
+func TestReportIrreversible(t *testing.T) {
+	alt := getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")
+	irreversible := NewReport(alt).Irreversible()
+
+	require.Len(t, irreversible, 1)
+	assert.Equal(t, "database", irreversible[0].Type)
+	assert.Equal(t, "db4", irreversible[0].Name)
+}
+
+func TestReportDestructive(t *testing.T) {
+	alt := getAlteredDatabases(t, "test/db/from.sql", "test/db/to.sql")
+	destructive := NewReport(alt).Destructive()
+
+	require.Len(t, destructive, 1)
+	assert.Equal(t, KindDropped, destructive[0].Kind)
+	assert.Equal(t, "database", destructive[0].Type)
+	assert.Equal(t, "db4", destructive[0].Name)
+}
@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/emirpasic/gods/stacks/arraystack"
@@ -25,6 +27,47 @@ var TypeDefaultFieldLen = map[string]string{
 	"decimal":   "10,0",
 }
 
+// Fingerprint returns a hash of the schemas, which is used to detect schema changes.
+// The result does not depend on the order of databases and tables.
+// AUTO_INCREMENT table option is ignored because it changes on every row insertion.
+func Fingerprint(schemas []*Schema) string {
+	strs := []string{}
+	for _, s := range schemas {
+		strs = append(strs, s.Database.String())
+		for _, t := range s.Tables {
+			table := *t
+			table.TableOptions.AutoIncrement = ""
+			strs = append(strs, table.String())
+		}
+	}
+	sort.Strings(strs)
+	sum := sha256.Sum256([]byte(strings.Join(strs, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// RenameDatabases returns copies of the schemas whose databases are renamed according to the map of old to new names.
+// Schemas of databases not in the map are returned as they are.
+func RenameDatabases(schemas []*Schema, names map[string]string) []*Schema {
+	ret := []*Schema{}
+	for _, s := range schemas {
+		name, ok := names[s.Database.DbName]
+		if !ok {
+			ret = append(ret, s)
+			continue
+		}
+		database := *s.Database
+		database.DbName = name
+		tables := []*parser.CreateTableStatement{}
+		for _, t := range s.Tables {
+			table := *t
+			table.DbName = name
+			tables = append(tables, &table)
+		}
+		ret = append(ret, &Schema{Database: &database, Tables: tables})
+	}
+	return ret
+}
+
 func (r Schema) String() string {
 	statements := []string{}
 
@@ -52,6 +95,78 @@ func NewSchemas(str string, config *parser.GlobalConfig, databases *hashset.Set)
 	return schema, nil
 }
 
+// SchemaSource is a chunk of schema SQL with its origin, such as a schema file.
+type SchemaSource struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+// NewSchemasFromSources parses multiple schema sources and merges them into schemas.
+// Each source is parsed independently, so a CREATE TABLE statement without database name must be preceded by
+// a USE statement in the same source.
+func NewSchemasFromSources(sources []SchemaSource, config *parser.GlobalConfig, databases *hashset.Set) ([]*Schema, error) {
+	var dbStatements []parser.Statement
+	var tableStatements []parser.Statement
+	dbOrigins := map[string]string{}
+	tableOrigins := map[string]string{}
+
+	for _, src := range sources {
+		p := parser.NewParser(strings.NewReader(src.Body))
+		statements, err := p.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema: %s : %w", src.Name, err)
+		}
+
+		defaultDbName := ""
+		for _, s := range statements {
+			switch st := s.(type) {
+			case parser.CreateDatabaseStatement:
+				if o, ok := dbOrigins[st.DbName]; ok {
+					return nil, fmt.Errorf("found duplicate CREATE DATABASE statement of `%s` in %s and %s", st.DbName, o, src.Name)
+				}
+				dbOrigins[st.DbName] = src.Name
+				dbStatements = append(dbStatements, st)
+			case parser.UseStatement:
+				defaultDbName = st.DbName
+			case parser.CreateTableStatement:
+				// Resolve the database name within the source, since USE statements do not carry over to other sources
+				if st.DbName == "" {
+					if defaultDbName == "" {
+						return nil, fmt.Errorf("found CREATE TABLE statement without database name in %s. statement: %s", src.Name, st.String())
+					}
+					st.DbName = defaultDbName
+				}
+				key := fmt.Sprintf("`%s`.`%s`", st.DbName, st.TableName)
+				if o, ok := tableOrigins[key]; ok {
+					return nil, fmt.Errorf("found duplicate CREATE TABLE statement of %s in %s and %s", key, o, src.Name)
+				}
+				tableOrigins[key] = src.Name
+				tableStatements = append(tableStatements, st)
+			}
+		}
+	}
+
+	// Databases must be declared before their tables regardless of the source order
+	statements := append(dbStatements, tableStatements...)
+
+	schema, err := normalizeStatements(statements, config, databases)
+	if err != nil {
+		return nil, fmt.Errorf("schema validation failed : %w", err)
+	}
+
+	return schema, nil
+}
+
+// NewSchemasFromStatements creates schemas from statements built without parsing, such as the ones built from
+// information_schema. The statements are normalized in the same way as parsed ones.
+func NewSchemasFromStatements(statements []parser.Statement, config *parser.GlobalConfig, databases *hashset.Set) ([]*Schema, error) {
+	schema, err := normalizeStatements(statements, config, databases)
+	if err != nil {
+		return nil, fmt.Errorf("schema validation failed : %w", err)
+	}
+	return schema, nil
+}
+
 func normalizeDataType(t interface{}) interface{} {
 	if it, ok := t.(parser.IntegerType); ok {
 		// Unset If field length is default
@@ -186,6 +301,7 @@ func normalizeStatements(statements []parser.Statement, config *parser.GlobalCon
 					foreignKeys = append(foreignKeys, &parser.ForeignKeyDefinition{
 						KeyPartList: []parser.KeyPart{{Column: v.ColumnName}},
 						ReferenceDefinition: parser.ReferenceDefinition{
+							DbName:           v.ColumnOptions.ReferenceDefinition.DbName,
 							TableName:        v.ColumnOptions.ReferenceDefinition.TableName,
 							KeyPartList:      v.ColumnOptions.ReferenceDefinition.KeyPartList,
 							ReferenceOptions: v.ColumnOptions.ReferenceDefinition.ReferenceOptions,
@@ -291,8 +407,12 @@ func normalizeStatements(statements []parser.Statement, config *parser.GlobalCon
 				return fkIndexes.Contains(e)
 			})
 
-			// Unset if reference option is RESTRICT or NO ACTION, which is default
 			for _, v := range foreignKeys {
+				// Unset if the referenced table is in the same database
+				if v.ReferenceDefinition.DbName == cts.DbName {
+					v.ReferenceDefinition.DbName = ""
+				}
+				// Unset if reference option is RESTRICT or NO ACTION, which is default
 				if Contains([]string{"RESTRICT", "NO ACTION"}, v.ReferenceDefinition.ReferenceOptions.OnDelete) {
 					v.ReferenceDefinition.ReferenceOptions.OnDelete = ""
 				}
@@ -1 +1,80 @@
 package lib
+
+import (
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewSchemasFromSources(t *testing.T) {
+	sources := []SchemaSource{
+		{Name: "db1/t2.sql", Body: "USE db1; CREATE TABLE t2 (id int);"},
+		{Name: "db1/_database.sql", Body: "CREATE DATABASE db1;"},
+		{Name: "db1/t1.sql", Body: "CREATE TABLE db1.t1 (id int);"},
+	}
+
+	schemas, err := NewSchemasFromSources(sources, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	require.Len(t, schemas, 1)
+	assert.Equal(t, "db1", schemas[0].Database.DbName)
+	require.Len(t, schemas[0].Tables, 2)
+	assert.Equal(t, "t2", schemas[0].Tables[0].TableName)
+	assert.Equal(t, "t1", schemas[0].Tables[1].TableName)
+}
+
+func TestNewSchemasFromSourcesWithoutUse(t *testing.T) {
+	sources := []SchemaSource{
+		{Name: "a.sql", Body: "CREATE DATABASE db1; USE db1;"},
+		{Name: "b.sql", Body: "CREATE TABLE t1 (id int);"},
+	}
+
+	_, err := NewSchemasFromSources(sources, TestDefaultGlobalConfig, hashset.New())
+	assert.ErrorContains(t, err, "without database name in b.sql")
+}
+
+func TestNewSchemasFromSourcesDuplicated(t *testing.T) {
+	sources := []SchemaSource{
+		{Name: "a.sql", Body: "CREATE DATABASE db1; USE db1; CREATE TABLE t1 (id int);"},
+		{Name: "b.sql", Body: "CREATE TABLE db1.t1 (id int);"},
+	}
+
+	_, err := NewSchemasFromSources(sources, TestDefaultGlobalConfig, hashset.New())
+	assert.ErrorContains(t, err, "duplicate CREATE TABLE statement of `db1`.`t1` in a.sql and b.sql")
+
+	sources = []SchemaSource{
+		{Name: "a.sql", Body: "CREATE DATABASE db1;"},
+		{Name: "b.sql", Body: "CREATE DATABASE db1;"},
+	}
+
+	_, err = NewSchemasFromSources(sources, TestDefaultGlobalConfig, hashset.New())
+	assert.ErrorContains(t, err, "duplicate CREATE DATABASE statement of `db1` in a.sql and b.sql")
+}
+
+func TestFingerprint(t *testing.T) {
+	s1, err := NewSchemas("CREATE DATABASE db1; USE db1; CREATE TABLE t1 (id int); CREATE TABLE t2 (id int) AUTO_INCREMENT = 10;", TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	s2, err := NewSchemas("CREATE DATABASE db1; USE db1; CREATE TABLE t2 (id int) AUTO_INCREMENT = 20; CREATE TABLE t1 (id int);", TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	s3, err := NewSchemas("CREATE DATABASE db1; USE db1; CREATE TABLE t1 (id bigint); CREATE TABLE t2 (id int);", TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, Fingerprint(s1), Fingerprint(s2))
+	assert.NotEqual(t, Fingerprint(s1), Fingerprint(s3))
+}
+
+func TestRenameDatabases(t *testing.T) {
+	schemas, err := NewSchemas("CREATE DATABASE db1; USE db1; CREATE TABLE t1 (id int); CREATE DATABASE db2;", TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	renamed := RenameDatabases(schemas, map[string]string{"db1": "db3"})
+	require.Len(t, renamed, 2)
+	assert.Equal(t, "db3", renamed[0].Database.DbName)
+	assert.Equal(t, "db3", renamed[0].Tables[0].DbName)
+	assert.Equal(t, "db2", renamed[1].Database.DbName)
+
+	// original schemas are not changed
+	assert.Equal(t, "db1", schemas[0].Database.DbName)
+	assert.Equal(t, "db1", schemas[0].Tables[0].DbName)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/emirpasic/gods/sets/linkedhashset"
 	"github.com/kota65535/alternator/parser"
 	"github.com/sirupsen/logrus"
+	"sort"
 	"strings"
 )
 
@@ -16,9 +17,15 @@ type TableAlterations struct {
 	Retained           []*RetainedTable
 	alterations        []Alteration
 	elementAlterations []Alteration
+	// If true, each clause is executed by its own ALTER TABLE statement
+	split bool
+	// renameCandidates are the pairs of dropped and added tables which may have been renamed
+	renameCandidates []*RenameCandidate
 }
 
-func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.CreateTableStatement) TableAlterations {
+// NewTableAlterations returns the table alterations.
+// Dropped and added tables or columns are renamed if their similarity score is at least the threshold.
+func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.CreateTableStatement, threshold float64) TableAlterations {
 
 	fromMap := map[string]*parser.CreateTableStatement{}
 	fromSet := linkedhashset.New()
@@ -70,13 +77,49 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 
 	renamedFrom := map[string]bool{}
 	renamedTo := map[string]bool{}
+	// renameAndModify renames the table, and modifies it as well if they differ
+	renameAndModify := func(t1 *parser.CreateTableStatement, t2 *parser.CreateTableStatement) {
+		elements := NewTableElementAlterations(t1, t2, threshold)
+
+		rt := &RenamedTable{
+			From:        t1,
+			To:          t2,
+			ForeignKeys: elements.ForeignKeys,
+			Sequential:  Sequential{tableOrder[t2.TableName]},
+		}
+		if !elements.Equivalent() {
+			// Foreign keys are altered as a part of the modification
+			rt.ForeignKeys = &ForeignKeyAlterations{}
+			from := *t1
+			from.TableName = t2.TableName
+			rt.Modified = newModifiedTable(&from, t2, elements, tableOrder[t2.TableName])
+		}
+		renamed = append(renamed, rt)
+		renamedFrom[t1.TableName] = true
+		renamedTo[t2.TableName] = true
+	}
+	// Tables with rename hints are renamed, and modified as well if they differ
+	for _, a := range addedOrRenamed {
+		for _, d := range droppedOrRenamed {
+			// Hints of 'from' tables are the ones renamed back, such as on reverting
+			hinted := a.This.RenamedFrom == d.This.TableName || d.This.RenamedFrom == a.This.TableName
+			if !hinted || renamedFrom[d.This.TableName] || renamedTo[a.This.TableName] {
+				continue
+			}
+			renameAndModify(fromMap[d.Id()], toMap[a.Id()])
+		}
+	}
 	// If two tables are equal except their names, assume they have been renamed
 	for _, a := range addedOrRenamed {
 		for _, d := range droppedOrRenamed {
+			if renamedTo[a.This.TableName] || renamedFrom[d.This.TableName] {
+				continue
+			}
 			t1 := fromMap[d.Id()]
 			t2 := toMap[a.Id()]
 
-			elements := NewTableElementAlterations(t1, t2)
+			// Columns are not renamed by similarity, since the tables are not equal then anyway
+			elements := NewTableElementAlterations(t1, t2, DefaultRenameThreshold)
 
 			if elements.Equivalent() {
 				// create ForeignKeyAlterations instance in case of column modification foreign key is referencing
@@ -91,6 +134,25 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 			}
 		}
 	}
+	// The rest of tables are paired by similarity, and renamed if they are similar enough
+	var fromRest []*parser.CreateTableStatement
+	var toRest []*parser.CreateTableStatement
+	for _, d := range droppedOrRenamed {
+		if !renamedFrom[d.This.TableName] {
+			fromRest = append(fromRest, d.This)
+		}
+	}
+	for _, a := range addedOrRenamed {
+		if !renamedTo[a.This.TableName] {
+			toRest = append(toRest, a.This)
+		}
+	}
+	candidates := tableRenameCandidates(fromRest, toRest, threshold)
+	for _, c := range candidates {
+		if c.Renamed {
+			renameAndModify(fromMap[c.From], toMap[c.To])
+		}
+	}
 
 	// Remove renamed tables from the lists
 	added := RemoveIf(addedOrRenamed, func(t *AddedTable) bool {
@@ -105,7 +167,7 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 		t1 := fromMap[s]
 		t2 := toMap[s]
 
-		elements := NewTableElementAlterations(t1, t2)
+		elements := NewTableElementAlterations(t1, t2, threshold)
 
 		if elements.Equivalent() {
 			// create ForeignKeyAlterations instance in case of column modification foreign key is referencing
@@ -115,23 +177,19 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 				Sequential:  Sequential{tableOrder[s]},
 			})
 		} else {
-			modified = append(modified, &ModifiedTable{
-				From:             t1,
-				To:               t2,
-				Columns:          elements.Columns,
-				PrimaryKeys:      elements.PrimaryKeys,
-				UniqueKeys:       elements.UniqueKeys,
-				ForeignKeys:      elements.ForeignKeys,
-				Indexes:          elements.Indexes,
-				FullTextIndexes:  elements.FullTextIndexes,
-				CheckConstraints: elements.CheckConstraints,
-				TableOptions:     elements.TableOptions,
-				Sequential:       Sequential{tableOrder[s]},
-			})
+			modified = append(modified, newModifiedTable(t1, t2, elements, tableOrder[s]))
 		}
 	}
 
-	for _, mt := range modified {
+	// Tables renamed by hints are modified after renamed
+	allModified := modified
+	for _, rt := range renamed {
+		if rt.Modified != nil {
+			allModified = append(allModified, rt.Modified)
+		}
+	}
+
+	for _, mt := range allModified {
 		// handle table renaming referred by foreign keys
 		for _, rt := range renamed {
 			mt.ForeignKeys.HandleTableRename(rt, rt.From.TableName, rt.To.TableName)
@@ -150,22 +208,22 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 		}
 	}
 
-	for _, t1 := range modified {
+	for _, t1 := range allModified {
 		// handle column drop referred by foreign keys
 		for _, c1 := range t1.Columns.Dropped {
-			for _, t2 := range modified {
+			for _, t2 := range allModified {
 				t2.ForeignKeys.HandleRefColumnDrop(c1, t1.To.TableName, c1.This.ColumnName)
 			}
 		}
 		// handle column rename referred by foreign keys
 		for _, c1 := range t1.Columns.Renamed {
-			for _, t2 := range modified {
+			for _, t2 := range allModified {
 				t2.ForeignKeys.HandleRefColumnRename(t1.To.TableName, c1.From.ColumnName, c1.To.ColumnName)
 			}
 		}
 		// handle column modification referred by foreign keys
 		for _, c1 := range t1.Columns.Modified {
-			for _, t2 := range modified {
+			for _, t2 := range allModified {
 				t2.ForeignKeys.HandleRefColumnModify(c1, t1.To.TableName, c1.To.ColumnName)
 			}
 			for _, t2 := range retained {
@@ -209,11 +267,12 @@ func NewTableAlterations(from []*parser.CreateTableStatement, to []*parser.Creat
 	}
 
 	return TableAlterations{
-		Added:    added,
-		Modified: modified,
-		Renamed:  renamed,
-		Dropped:  dropped,
-		Retained: retained,
+		Added:            added,
+		Modified:         modified,
+		Renamed:          renamed,
+		Dropped:          dropped,
+		Retained:         retained,
+		renameCandidates: candidates,
 	}
 }
 
@@ -228,33 +287,57 @@ type TableElementAlterations struct {
 	TableOptions     *TableOptionAlterations
 }
 
-func NewTableElementAlterations(t1 *parser.CreateTableStatement, t2 *parser.CreateTableStatement) TableElementAlterations {
-	columns := NewColumnAlterations(t1.GetColumns(), t2.GetColumns())
+func NewTableElementAlterations(t1 *parser.CreateTableStatement, t2 *parser.CreateTableStatement, threshold float64) TableElementAlterations {
+	candidates := columnRenameCandidates(t1, t2, threshold)
+	renames := map[string]string{}
+	for _, c := range candidates {
+		if c.Renamed {
+			renames[c.From] = c.To
+		}
+	}
+	columns := NewColumnAlterations(t1.GetColumns(), t2.GetColumns(), renames)
+	columns.RenameCandidates = columns.actualRenameCandidates(candidates)
 
-	// Handle column rename
+	// Handle column rename.
+	// Key definitions are copied, since the same table is compared with others as well on detecting table renames.
+	pks := t1.GetPrimaryKeys()
+	uks := t1.GetUniqueKeys()
+	idxs := t1.GetIndexes()
+	ftxs := t1.GetFullTextIndexes()
+	fks := t1.GetForeignKeys()
 	for _, c := range columns.Renamed {
-		for _, p := range t1.GetPrimaryKeys() {
-			p.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+		for i, p := range pks {
+			d := *p
+			d.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+			pks[i] = &d
 		}
-		for _, p := range t1.GetUniqueKeys() {
-			p.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+		for i, p := range uks {
+			d := *p
+			d.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+			uks[i] = &d
 		}
-		for _, p := range t1.GetIndexes() {
-			p.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+		for i, p := range idxs {
+			d := *p
+			d.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+			idxs[i] = &d
 		}
-		for _, p := range t1.GetFullTextIndexes() {
-			p.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+		for i, p := range ftxs {
+			d := *p
+			d.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+			ftxs[i] = &d
 		}
-		for _, p := range t1.GetForeignKeys() {
-			p.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+		for i, p := range fks {
+			d := *p
+			d.KeyPartList = keyPartReplace(p.KeyPartList, c.From.ColumnName, c.To.ColumnName)
+			fks[i] = &d
 		}
 	}
 
-	primaryKeys := NewPrimaryKeyAlterations(t1.GetPrimaryKeys(), t2.GetPrimaryKeys(), columns.ColumnOrder)
-	uniqueKeys := NewUniqueAlterations(t1.GetUniqueKeys(), t2.GetUniqueKeys(), columns.ColumnOrder)
-	indexes := NewIndexAlterations(t1.GetIndexes(), t2.GetIndexes(), columns.ColumnOrder)
-	fullTextIndexes := NewFullTextIndexAlteration(t1.GetFullTextIndexes(), t2.GetFullTextIndexes(), columns.ColumnOrder)
-	foreignKeys := NewForeignKeyAlterations(t1.GetForeignKeys(), t2.GetForeignKeys(), columns.ColumnOrder)
+	primaryKeys := NewPrimaryKeyAlterations(pks, t2.GetPrimaryKeys(), columns.ColumnOrder)
+	uniqueKeys := NewUniqueAlterations(uks, t2.GetUniqueKeys(), columns.ColumnOrder)
+	indexes := NewIndexAlterations(idxs, t2.GetIndexes(), columns.ColumnOrder)
+	fullTextIndexes := NewFullTextIndexAlteration(ftxs, t2.GetFullTextIndexes(), columns.ColumnOrder)
+	foreignKeys := NewForeignKeyAlterations(fks, t2.GetForeignKeys(), columns.ColumnOrder)
 	checkConstraints := NewCheckConstraintAlterations(t1.GetCheckConstraints(), t2.GetCheckConstraints())
 	tableOptions := NewTableOptionAlterations(&t1.TableOptions, &t2.TableOptions)
 
@@ -281,25 +364,174 @@ func (r TableElementAlterations) Equivalent() bool {
 		r.TableOptions.Equivalent()
 }
 
+// RenameCandidates returns the pairs of dropped and added tables or columns which may have been renamed
+func (r *TableAlterations) RenameCandidates() []*RenameCandidate {
+	ret := append([]*RenameCandidate{}, r.renameCandidates...)
+	for _, t := range r.Modified {
+		ret = append(ret, t.Columns.RenameCandidates...)
+	}
+	for _, t := range r.Renamed {
+		if t.Modified != nil {
+			ret = append(ret, t.Modified.Columns.RenameCandidates...)
+		}
+	}
+	return ret
+}
+
 func (r TableAlterations) Statements() []string {
 	ret := []string{}
+	for _, g := range r.statementGroups() {
+		ret = append(ret, g.Statement())
+	}
+	return ret
+}
+
+// statementGroups returns the table element alterations grouped by the statements executing them.
+// Unless split, the alterations are leveled by their dependencies, and the clauses altering the same table at the same
+// level are merged into one ALTER TABLE statement. So each table is altered by at most one statement per level.
+func (r *TableAlterations) statementGroups() []*statementGroup {
+	ret := []*statementGroup{}
+	if r.split {
+		for _, a := range r.TableElementAlterations() {
+			for _, s := range completeStatements(a, a.Prefix()) {
+				ret = append(ret, &statementGroup{alterations: []Alteration{a}, statement: s})
+			}
+		}
+		return ret
+	}
+
+	type groupKey struct {
+		level  int
+		prefix string
+	}
+	groups := map[groupKey]*statementGroup{}
+	// levels of the last statements of the alterations
+	levels := map[Alteration]int{}
+	tableLevels := map[string]int{}
+	maxLevel := -1
+	barrier := 0
 	for _, a := range r.TableElementAlterations() {
-		statements := []string{}
-		for _, s := range a.Statements() {
-			statements = append(statements, a.Prefix()+s)
-		}
-		statements = parser.Align(statements)
-		for _, s := range statements {
-			// TODO: more smartly
-			if !strings.HasSuffix(s, ";") {
-				s += ";"
+		statements := a.Statements()
+		if len(statements) == 0 {
+			continue
+		}
+		level := barrier
+		for b, l := range levels {
+			if mustFollow(a, b) {
+				level = max(level, l+1)
 			}
-			ret = append(ret, s)
 		}
+		if a.Prefix() == "" {
+			// Statements without prefix, such as CREATE TABLE, are executed as they are after all the preceding ones
+			level = max(level, maxLevel+1)
+			for i, s := range completeStatements(a, "") {
+				ret = append(ret, &statementGroup{level: level + i, alterations: []Alteration{a}, statement: s})
+			}
+			levels[a] = level + len(statements) - 1
+			maxLevel = levels[a]
+			// The following ones must not be executed before them
+			barrier = maxLevel + 1
+			continue
+		}
+		// Clauses of the same table are executed in order, except that foreign keys can be dropped in advance
+		if _, ok := a.(*DroppedForeignKey); !ok {
+			level = max(level, tableLevels[a.Prefix()])
+		}
+		for i, s := range parser.Align(statements) {
+			if i > 0 && sequentialStatements(a) {
+				level++
+			}
+			key := groupKey{level, a.Prefix()}
+			if _, ok := groups[key]; !ok {
+				groups[key] = &statementGroup{level: level, prefix: a.Prefix()}
+				ret = append(ret, groups[key])
+			}
+			groups[key].add(a, s)
+		}
+		levels[a] = level
+		tableLevels[a.Prefix()] = max(tableLevels[a.Prefix()], level)
+		maxLevel = max(maxLevel, level)
 	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].level < ret[j].level })
 	return ret
 }
 
+// statementGroup is a set of table element alterations executed by one statement
+type statementGroup struct {
+	// level is the depth of the statement in the dependencies
+	level       int
+	prefix      string
+	alterations []Alteration
+	clauses     []string
+	// statement is set if the alteration is executed as it is
+	statement string
+}
+
+// Statement returns the executable statement
+func (r statementGroup) Statement() string {
+	if r.statement != "" {
+		return r.statement
+	}
+	return r.prefix + strings.Join(r.clauses, ", ") + ";"
+}
+
+func (r *statementGroup) add(a Alteration, clause string) {
+	if !Contains(r.alterations, a) {
+		r.alterations = append(r.alterations, a)
+	}
+	r.clauses = append(r.clauses, strings.TrimSuffix(strings.TrimSpace(clause), ";"))
+}
+
+// mustFollow returns true if the alteration a must be executed by a statement after the one executing b
+func mustFollow(a Alteration, b Alteration) bool {
+	if Contains(a.DependsOn(), b) {
+		return true
+	}
+	// A check constraint cannot be added with the name of the one dropped in the same statement
+	if v, ok := a.(*AddedCheckConstraint); ok && v.This.ConstraintName != "" {
+		if d, ok := b.(*DroppedCheckConstraint); ok && d.This.ConstraintName == v.This.ConstraintName && a.Prefix() == b.Prefix() {
+			return true
+		}
+	}
+	// An index cannot be added with the old name of the one renamed in the same statement
+	if name := addedIndexName(a); name != "" && name == renamedIndexName(b) && a.Prefix() == b.Prefix() {
+		return true
+	}
+	return false
+}
+
+func addedIndexName(a Alteration) string {
+	switch v := a.(type) {
+	case *AddedIndex:
+		return v.This.IndexName
+	case *AddedUniqueKey:
+		return v.This.IndexName
+	case *AddedFullTextIndex:
+		return v.This.IndexName
+	}
+	return ""
+}
+
+// renamedIndexName returns the old name of the renamed index
+func renamedIndexName(a Alteration) string {
+	switch v := a.(type) {
+	case *RenamedIndex:
+		return v.From.IndexName
+	case *RenamedUniqueKey:
+		return v.From.IndexName
+	case *RenamedFullTextIndex:
+		return v.From.IndexName
+	}
+	return ""
+}
+
+// sequentialStatements returns true if the statements of the alteration must be executed one by one,
+// such as dropping a foreign key before dropping its index
+func sequentialStatements(a Alteration) bool {
+	_, ok := a.(*DroppedForeignKey)
+	return ok
+}
+
 func (r TableAlterations) Diff() []string {
 	ret := []string{}
 	for _, a := range r.Alterations() {
@@ -420,6 +652,22 @@ func (r AddedTable) Id() string {
 	return r.This.TableName
 }
 
+func newModifiedTable(from *parser.CreateTableStatement, to *parser.CreateTableStatement, elements TableElementAlterations, seq int) *ModifiedTable {
+	return &ModifiedTable{
+		From:             from,
+		To:               to,
+		Columns:          elements.Columns,
+		PrimaryKeys:      elements.PrimaryKeys,
+		UniqueKeys:       elements.UniqueKeys,
+		ForeignKeys:      elements.ForeignKeys,
+		Indexes:          elements.Indexes,
+		FullTextIndexes:  elements.FullTextIndexes,
+		CheckConstraints: elements.CheckConstraints,
+		TableOptions:     elements.TableOptions,
+		Sequential:       Sequential{seq},
+	}
+}
+
 type ModifiedTable struct {
 	From             *parser.CreateTableStatement
 	To               *parser.CreateTableStatement
@@ -602,6 +850,8 @@ type RenamedTable struct {
 	From        *parser.CreateTableStatement
 	To          *parser.CreateTableStatement
 	ForeignKeys *ForeignKeyAlterations
+	// Modified is set if the table renamed by a hint is also modified
+	Modified *ModifiedTable
 	Sequential
 	Dependent
 	Prefixable
@@ -611,6 +861,12 @@ func (r *RenamedTable) Alterations() []Alteration {
 	alterations := []Alteration{}
 	alterations = append(alterations, r)
 	alterations = append(alterations, r.ForeignKeys.Alterations()...)
+	if r.Modified != nil {
+		for _, a := range r.Modified.Alterations() {
+			a.AddDependsOn(r)
+			alterations = append(alterations, a)
+		}
+	}
 	return alterations
 }
 
@@ -628,6 +884,9 @@ func (r RenamedTable) Statements() []string {
 func (r RenamedTable) Diff() []string {
 	from := prefix(r.From.String(), "  ")
 	to := prefix(r.To.String(), "  ")
+	if r.Modified != nil {
+		to = r.Modified.Diff()[0]
+	}
 	fromHead, _, _ := strings.Cut(from, "\n")
 	toHead, _, _ := strings.Cut(to, "\n")
 	ret := strings.Replace(to, toHead, fmt.Sprintf("~ %s -> %s", fromHead[2:], toHead[2:]), 1)
@@ -716,7 +975,8 @@ func getTableDependencies(statements []*parser.CreateTableStatement) map[string]
 	ret := map[string][]string{}
 	for _, s := range statements {
 		for _, d := range s.CreateDefinitions {
-			if fk, ok := d.(*parser.ForeignKeyDefinition); ok {
+			// Tables in other databases are not the dependencies
+			if fk, ok := d.(*parser.ForeignKeyDefinition); ok && fk.ReferenceDefinition.DbName == "" {
 				if !Contains(ret[s.TableName], fk.ReferenceDefinition.TableName) {
 					ret[s.TableName] = append(ret[s.TableName], fk.ReferenceDefinition.TableName)
 				}
@@ -726,6 +986,24 @@ func getTableDependencies(statements []*parser.CreateTableStatement) map[string]
 	return ret
 }
 
+// completeStatements returns executable statements of the alteration with the given prefix
+func completeStatements(a Alteration, prefix string) []string {
+	ret := []string{}
+	statements := []string{}
+	for _, s := range a.Statements() {
+		statements = append(statements, prefix+s)
+	}
+	statements = parser.Align(statements)
+	for _, s := range statements {
+		// TODO: more smartly
+		if !strings.HasSuffix(s, ";") {
+			s += ";"
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
 func createStatements(db string, table string, statements []string) []string {
 	ret := []string{}
 	for _, s := range statements {
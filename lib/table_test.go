@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -43,3 +44,113 @@ func TestGetAlteredTables(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(b4), strings.Join(diffTo, "\n"))
 }
+
+func TestMergedStatements(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t0 (id int PRIMARY KEY);
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 int,
+    CONSTRAINT fk1 FOREIGN KEY fk1 (c1) REFERENCES t0 (id)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t0 (id int PRIMARY KEY);
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    c1 int,
+    c2 int,
+    c3 int,
+    INDEX idx2 (c2),
+    INDEX idx3 (c3)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// The index of the foreign key is dropped after the foreign key
+	alt := NewDatabaseAlterations(from, to)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `c2` int AFTER `c1`, ADD COLUMN `c3` int AFTER `c2`, " +
+			"ADD INDEX `idx2` (`c2`), ADD INDEX `idx3` (`c3`), DROP FOREIGN KEY `fk1`;",
+		"ALTER TABLE `db1`.`t1` DROP INDEX `fk1`;",
+	}, alt.Statements())
+
+	alt.SetSplitStatements(true)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `c2` int AFTER `c1`;",
+		"ALTER TABLE `db1`.`t1` ADD COLUMN `c3` int AFTER `c2`;",
+		"ALTER TABLE `db1`.`t1` ADD INDEX `idx2` (`c2`);",
+		"ALTER TABLE `db1`.`t1` ADD INDEX `idx3` (`c3`);",
+		"ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `fk1`;",
+		"ALTER TABLE `db1`.`t1` DROP INDEX `fk1`;",
+	}, alt.Statements())
+	assert.Equal(t, alt.Statements(), alt.Reverse().Reverse().Statements())
+}
+
+func TestRenameHints(t *testing.T) {
+	from, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    name varchar(10),
+    code int,
+    INDEX idx1 (code)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	to, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+-- alternator:renamed-from t1
+CREATE TABLE t2 (
+    id int PRIMARY KEY,
+    -- alternator:renamed-from name
+    full_name varchar(100),
+    code_number bigint, -- alternator:renamed-from code
+    INDEX idx2 (code_number) INVISIBLE -- alternator:renamed-from idx1
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+
+	// The table and the column are renamed along with their modifications, instead of dropped and added
+	alt := NewDatabaseAlterations(from, to)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` RENAME TO `db1`.`t2`;",
+		"ALTER TABLE `db1`.`t2` CHANGE COLUMN `name` `full_name` varchar(100), CHANGE COLUMN `code` `code_number` bigint, " +
+			"RENAME INDEX `idx1` TO `idx2`;",
+		"ALTER TABLE `db1`.`t2` ALTER INDEX `idx2` INVISIBLE;",
+	}, alt.Statements())
+
+	// Hints also rename them back on reverting
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t2` RENAME TO `db1`.`t1`;",
+		"ALTER TABLE `db1`.`t1` CHANGE COLUMN `full_name` `name` varchar(10), CHANGE COLUMN `code_number` `code` int, " +
+			"RENAME INDEX `idx2` TO `idx1`;",
+		"ALTER TABLE `db1`.`t1` ALTER INDEX `idx1` VISIBLE;",
+	}, alt.Reverse().Statements())
+
+	// Hints are ignored once they have been applied
+	alt = NewDatabaseAlterations(to, to)
+	assert.Empty(t, alt.Statements())
+
+	// Hints naming the column itself are ignored
+	self, err := NewSchemas(`
+CREATE DATABASE db1;
+USE db1;
+CREATE TABLE t1 (
+    id int PRIMARY KEY,
+    name varchar(10),
+    code bigint, -- alternator:renamed-from code
+    INDEX idx1 (code)
+);`, TestDefaultGlobalConfig, hashset.New())
+	require.NoError(t, err)
+	alt = NewDatabaseAlterations(from, self)
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `code` bigint;",
+	}, alt.Statements())
+	assert.Equal(t, []string{
+		"ALTER TABLE `db1`.`t1` MODIFY COLUMN `code` int;",
+	}, alt.Reverse().Statements())
+}
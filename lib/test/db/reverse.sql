@@ -0,0 +1,19 @@
+ALTER TABLE `db1`.`t13` RENAME TO `db1`.`t11`;
+ALTER TABLE `db1`.`t12` DROP FOREIGN KEY `fk_t12_varchar1`;
+ALTER TABLE `db1`.`t12` DROP INDEX `fk_t12_varchar1`;
+ALTER TABLE `db1`.`t12` DROP COLUMN `varchar1`;
+ALTER DATABASE `db2` DEFAULT COLLATE = utf8mb4_0900_ai_ci;
+ALTER TABLE `db2`.`t21` DEFAULT COLLATE = utf8mb4_0900_ai_ci;
+ALTER TABLE `db2`.`t22` DEFAULT COLLATE = utf8mb4_0900_ai_ci, DROP FOREIGN KEY `fk_t22_varchar1`;
+ALTER TABLE `db2`.`t22` DROP INDEX `fk_t22_varchar1`;
+ALTER TABLE `db2`.`t21` MODIFY COLUMN `varchar1` varchar(10);
+ALTER TABLE `db2`.`t22` DROP COLUMN `varchar1`;
+CREATE DATABASE `db4`
+    DEFAULT COLLATE = utf8mb4_bin;
+CREATE TABLE `db4`.`t41`
+(
+    `int1`     int         NOT NULL,
+    `varchar1` varchar(10),
+    PRIMARY KEY (`int1`)
+);
+DROP DATABASE `db3`;
\ No newline at end of file
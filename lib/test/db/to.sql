@@ -21,7 +21,7 @@ CREATE TABLE `t12`
     # retained, referencing renamed table
     FOREIGN KEY (`int2`) REFERENCES `t13` (`int1`),
     # added, referencing renamed table
-    FOREIGN KEY (`varchar1`) REFERENCES `t13` (`varchar1`)
+    CONSTRAINT `fk_t12_varchar1` FOREIGN KEY (`varchar1`) REFERENCES `t13` (`varchar1`)
 );
 
 # modified
@@ -49,7 +49,7 @@ CREATE TABLE `t22`
     # retained, referencing renamed table
     FOREIGN KEY (`int2`) REFERENCES `t21` (`int1`),
     # added, referencing renamed table
-    FOREIGN KEY (`varchar1`) REFERENCES `t21` (`varchar1`)
+    CONSTRAINT `fk_t22_varchar1` FOREIGN KEY (`varchar1`) REFERENCES `t21` (`varchar1`)
 );
 
 # added
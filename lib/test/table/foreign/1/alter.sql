@@ -1,18 +1,4 @@
-ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int2`)'>`;
-ALTER TABLE `db1`.`t1` DROP INDEX `<unknown index name of 'FOREIGN KEY (`int2`)'>`;
-ALTER TABLE `db1`.`t1` ADD FOREIGN KEY (`int2`) REFERENCES `t0` (`int1`) ON UPDATE CASCADE;
-ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int3`)'>`;
-ALTER TABLE `db1`.`t1` DROP INDEX `fk1`;
-ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int4`)'>`;
-ALTER TABLE `db1`.`t1` DROP INDEX `fk2`;
-ALTER TABLE `db1`.`t1` ADD FOREIGN KEY `fk1` (`int5`) REFERENCES `t0` (`int2`);
-ALTER TABLE `db1`.`t1` ADD FOREIGN KEY `fk3` (`int6`) REFERENCES `t0` (`int1`);
-ALTER TABLE `db1`.`t2` DROP FOREIGN KEY `c2`;
-ALTER TABLE `db1`.`t2` DROP INDEX `c2`;
-ALTER TABLE `db1`.`t2` ADD CONSTRAINT `c2` FOREIGN KEY `c2` (`int2`) REFERENCES `t0` (`int2`) ON UPDATE CASCADE;
-ALTER TABLE `db1`.`t2` DROP FOREIGN KEY `c3`;
-ALTER TABLE `db1`.`t2` DROP INDEX `c3`;
-ALTER TABLE `db1`.`t2` DROP FOREIGN KEY `c4`;
-ALTER TABLE `db1`.`t2` DROP INDEX `c4`;
-ALTER TABLE `db1`.`t2` ADD CONSTRAINT `c3` FOREIGN KEY `c3` (`int5`) REFERENCES `t0` (`int2`);
-ALTER TABLE `db1`.`t2` ADD CONSTRAINT `c4` FOREIGN KEY `c4` (`int6`) REFERENCES `t0` (`int1`);
\ No newline at end of file
+ALTER TABLE `db1`.`t1` DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int2`)'>`, DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int3`)'>`, DROP FOREIGN KEY `<unknown constraint name of 'FOREIGN KEY (`int4`)'>`;
+ALTER TABLE `db1`.`t2` DROP FOREIGN KEY `c2`, DROP FOREIGN KEY `c3`, DROP FOREIGN KEY `c4`;
+ALTER TABLE `db1`.`t1` DROP INDEX `<unknown index name of 'FOREIGN KEY (`int2`)'>`, ADD FOREIGN KEY (`int2`) REFERENCES `t0` (`int1`) ON UPDATE CASCADE, DROP INDEX `fk1`, DROP INDEX `fk2`, ADD FOREIGN KEY `fk1` (`int5`) REFERENCES `t0` (`int2`), ADD FOREIGN KEY `fk3` (`int6`) REFERENCES `t0` (`int1`);
+ALTER TABLE `db1`.`t2` DROP INDEX `c2`, ADD CONSTRAINT `c2` FOREIGN KEY `c2` (`int2`) REFERENCES `t0` (`int2`) ON UPDATE CASCADE, DROP INDEX `c3`, DROP INDEX `c4`, ADD CONSTRAINT `c3` FOREIGN KEY `c3` (`int5`) REFERENCES `t0` (`int2`), ADD CONSTRAINT `c4` FOREIGN KEY `c4` (`int6`) REFERENCES `t0` (`int1`);
\ No newline at end of file
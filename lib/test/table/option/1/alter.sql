@@ -1,2 +1 @@
-ALTER TABLE `db1`.`t1` AUTO_INCREMENT = 10;
-ALTER TABLE `db1`.`t1` MAX_ROWS = 200;
\ No newline at end of file
+ALTER TABLE `db1`.`t1` AUTO_INCREMENT = 10, MAX_ROWS = 200;
\ No newline at end of file
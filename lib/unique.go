@@ -78,11 +78,25 @@ func NewUniqueAlterations(
 				Sequential: Sequential{uniqueKeyOrder[s]},
 			})
 		} else if t2.IndexName != "" && t1.IndexName != t2.IndexName {
-			renamed = append(renamed, &RenamedUniqueKey{
+			ru := &RenamedUniqueKey{
 				From:       t1,
 				To:         t2,
 				Sequential: Sequential{uniqueKeyOrder[s]},
-			})
+			}
+			renamed = append(renamed, ru)
+			// Renamed by a hint, and then modified with the new name
+			hinted := t2.RenamedFrom == t1.IndexName || t1.RenamedFrom == t2.IndexName
+			if hinted && !reflect.DeepEqual(t1.IndexOptions, t2.IndexOptions) {
+				from := *t1
+				from.IndexName = t2.IndexName
+				mu := &ModifiedUniqueKey{
+					From:       &from,
+					To:         t2,
+					Sequential: Sequential{uniqueKeyOrder[s]},
+				}
+				mu.AddDependsOn(ru)
+				modified = append(modified, mu)
+			}
 		} else {
 			modified = append(modified, &ModifiedUniqueKey{
 				From:       t1,
@@ -221,11 +235,14 @@ type ModifiedUniqueKey struct {
 
 func (r ModifiedUniqueKey) Statements() []string {
 	indexName := r.From.IndexName
+	if indexName == "" {
+		indexName = r.To.IndexName
+	}
 	if indexName == "" {
 		indexName = fmt.Sprintf("<unknown index name of '%s'>", r.From.StringKeyPartList())
 	}
 	return []string{fmt.Sprintf("ALTER INDEX `%s`%s", indexName,
-		optS(r.To.IndexOptions.Diff(r.From.IndexOptions).String(), " %s"))}
+		optS(indexOptionsDiff(r.From.IndexOptions, r.To.IndexOptions).String(), " %s"))}
 }
 
 func (r ModifiedUniqueKey) Diff() []string {
@@ -364,5 +381,7 @@ func uniqueDefsEqual(from parser.UniqueKeyDefinition, to parser.UniqueKeyDefinit
 	to.IndexName = ""
 	from.ConstraintName = ""
 	to.ConstraintName = ""
+	from.RenamedFrom = ""
+	to.RenamedFrom = ""
 	return reflect.DeepEqual(from, to) && (ti == "" || fi == ti) && (tc == "" || fc == tc)
 }
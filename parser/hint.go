@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"github.com/kota65535/alternator/lexer"
+	"regexp"
+	"strings"
+)
+
+// renamedFromRegexp matches the comment giving the old name of a table, column, index or constraint
+var renamedFromRegexp = regexp.MustCompile("^alternator:renamed-from\\s+(?:`([^`]+)`|([A-Za-z0-9_$]+))")
+
+// hintTarget is a statement, or a definition of the CREATE TABLE statement if definition is not -1
+type hintTarget struct {
+	statement  int
+	definition int
+}
+
+// renameHints collects comments such as "-- alternator:renamed-from old_name" while lexing.
+// A comment at the end of a line is for the table or the definition started last, and the other comments are for the
+// one starting next.
+type renameHints struct {
+	hints   map[hintTarget]string
+	current *hintTarget
+	pending string
+	// line of the last token, or -1 if no token is scanned
+	lastLine int
+
+	statement        int
+	inStatement      bool
+	createStatement  bool
+	createTable      bool
+	depth            int
+	definition       int
+	inDefinitions    bool
+	definitionsDone  bool
+	expectDefinition bool
+}
+
+func newRenameHints() *renameHints {
+	return &renameHints{
+		hints:     map[hintTarget]string{},
+		lastLine:  -1,
+		statement: -1,
+	}
+}
+
+// scanned tracks where the token is in statements and CREATE TABLE definitions
+func (r *renameHints) scanned(t *lexer.Token) {
+	id := int(t.Type.GetID())
+	defer func() { r.lastLine = t.Position.Line }()
+
+	if !r.inStatement {
+		if id == semicolon {
+			return
+		}
+		r.statement++
+		r.inStatement = true
+		r.createStatement = id == CREATE
+		r.createTable = false
+		r.depth = 0
+		r.definition = -1
+		r.inDefinitions = false
+		r.definitionsDone = false
+		r.expectDefinition = false
+		r.start(hintTarget{r.statement, -1})
+	}
+	if r.expectDefinition && id != rp {
+		r.definition++
+		r.expectDefinition = false
+		r.start(hintTarget{r.statement, r.definition})
+	}
+
+	switch id {
+	case TABLE:
+		if r.createStatement && r.depth == 0 && !r.inDefinitions && !r.definitionsDone {
+			r.createTable = true
+		}
+	case lp:
+		if r.createTable && r.depth == 0 && !r.inDefinitions && !r.definitionsDone {
+			r.inDefinitions = true
+			r.expectDefinition = true
+		}
+		r.depth++
+	case rp:
+		r.depth--
+		if r.inDefinitions && r.depth == 0 {
+			r.inDefinitions = false
+			r.definitionsDone = true
+			r.expectDefinition = false
+		}
+	case comma:
+		if r.inDefinitions && r.depth == 1 {
+			r.expectDefinition = true
+		}
+	case semicolon:
+		if r.depth == 0 {
+			r.inStatement = false
+			r.current = nil
+		}
+	}
+}
+
+// skipped records the hint if the skipped token is the comment giving the old name
+func (r *renameHints) skipped(t *lexer.Token) {
+	name, ok := parseRenamedFrom(t.Literal)
+	if !ok {
+		return
+	}
+	if r.lastLine == t.Position.Line && r.current != nil {
+		r.hints[*r.current] = name
+		return
+	}
+	r.pending = name
+}
+
+func (r *renameHints) start(target hintTarget) {
+	r.current = &target
+	if r.pending != "" {
+		r.hints[target] = r.pending
+		r.pending = ""
+	}
+}
+
+// apply sets the old names to the statements and the definitions
+func (r *renameHints) apply(statements []Statement) {
+	if len(r.hints) == 0 {
+		return
+	}
+	i := -1
+	for j, s := range statements {
+		if s == nil {
+			continue
+		}
+		i++
+		cts, ok := s.(CreateTableStatement)
+		if !ok {
+			continue
+		}
+		cts.RenamedFrom = r.hints[hintTarget{i, -1}]
+		for k, d := range cts.CreateDefinitions {
+			name, ok := r.hints[hintTarget{i, k}]
+			if !ok {
+				continue
+			}
+			switch v := d.(type) {
+			case *ColumnDefinition:
+				v.RenamedFrom = name
+			case *IndexDefinition:
+				v.RenamedFrom = name
+			case *FullTextIndexDefinition:
+				v.RenamedFrom = name
+			case *UniqueKeyDefinition:
+				v.RenamedFrom = name
+			case *ForeignKeyDefinition:
+				v.RenamedFrom = name
+			case *CheckConstraintDefinition:
+				v.RenamedFrom = name
+			}
+		}
+		statements[j] = cts
+	}
+}
+
+// parseRenamedFrom returns the old name if the comment is "alternator:renamed-from <name>"
+func parseRenamedFrom(comment string) (string, bool) {
+	comment = strings.TrimSpace(comment)
+	for _, p := range []string{"--", "#", "/*"} {
+		if strings.HasPrefix(comment, p) {
+			comment = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(comment, p), "*/"))
+			break
+		}
+	}
+	m := renamedFromRegexp.FindStringSubmatch(comment)
+	if m == nil {
+		return "", false
+	}
+	return m[1] + m[2], true
+}
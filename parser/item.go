@@ -82,9 +82,13 @@ type ColumnDefinition struct {
 	ColumnName    string
 	DataType      interface{}
 	ColumnOptions ColumnOptions
+	// RenamedFrom is the old column name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r ColumnDefinition) Equals(a ColumnDefinition) bool {
+	r.RenamedFrom = ""
+	a.RenamedFrom = ""
 	// Trim optional quotes of DEFAULT value
 	r.ColumnOptions.Default = strings.Trim(r.ColumnOptions.Default, "'")
 	a.ColumnOptions.Default = strings.Trim(a.ColumnOptions.Default, "'")
@@ -334,13 +338,16 @@ func (t SpatialType) String() string {
 }
 
 type ReferenceDefinition struct {
+	// Database name of the referenced table, which is empty if it is in the same database
+	DbName           string
 	TableName        string
 	KeyPartList      []KeyPart
 	ReferenceOptions ReferenceOptions
 }
 
 func (r ReferenceDefinition) String() string {
-	return fmt.Sprintf("REFERENCES `%s` (%s)%s",
+	return fmt.Sprintf("REFERENCES %s`%s` (%s)%s",
+		optS(r.DbName, "`%s`."),
 		r.TableName,
 		JoinT(r.KeyPartList, ", ", ""),
 		optS(r.ReferenceOptions.String(), " %s"))
@@ -374,6 +381,8 @@ type CheckConstraintDefinition struct {
 	ConstraintName         string
 	Check                  string
 	CheckConstraintOptions CheckConstraintOptions
+	// RenamedFrom is the old constraint name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r CheckConstraintDefinition) String() string {
@@ -427,6 +436,8 @@ type IndexDefinition struct {
 	IndexName    string
 	KeyPartList  []KeyPart
 	IndexOptions IndexOptions
+	// RenamedFrom is the old index name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r IndexDefinition) EqualsExceptIndexName(a IndexDefinition) bool {
@@ -456,6 +467,8 @@ type FullTextIndexDefinition struct {
 	IndexName    string
 	KeyPartList  []KeyPart
 	IndexOptions IndexOptions
+	// RenamedFrom is the old index name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r FullTextIndexDefinition) String() string {
@@ -491,6 +504,8 @@ type UniqueKeyDefinition struct {
 	IndexName      string
 	KeyPartList    []KeyPart
 	IndexOptions   IndexOptions
+	// RenamedFrom is the old index name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r UniqueKeyDefinition) String() string {
@@ -547,6 +562,8 @@ type ForeignKeyDefinition struct {
 	IndexName           string
 	KeyPartList         []KeyPart
 	ReferenceDefinition ReferenceDefinition
+	// RenamedFrom is the old constraint name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r ForeignKeyDefinition) String() string {
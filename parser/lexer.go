@@ -13,6 +13,7 @@ type Parser struct {
 	lastToken *lexer.Token
 	result    []Statement
 	lastError error
+	hints     *renameHints
 }
 
 func NewParser(reader io.Reader) *Parser {
@@ -47,9 +48,12 @@ func NewParser(reader io.Reader) *Parser {
 	}
 
 	l := lexer.NewLexer(reader, tokens, skippedTokens)
+	hints := newRenameHints()
+	l.SkipHandler = hints.skipped
 
 	return &Parser{
 		lexer: l,
+		hints: hints,
 	}
 }
 
@@ -58,6 +62,7 @@ func (p *Parser) Parse() ([]Statement, error) {
 	if ret != 0 {
 		return nil, p.LastError()
 	}
+	p.hints.apply(p.result)
 	return p.result, nil
 }
 
@@ -74,6 +79,7 @@ func (p *Parser) Lex(lval *yySymType) int {
 	lval.token = token
 
 	p.lastToken = token
+	p.hints.scanned(token)
 
 	logrus.Debugf("token '%s' as %s\n", token.Literal, token.Type.GetID())
 
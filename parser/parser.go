@@ -2536,6 +2536,7 @@ yydefault:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyVAL.item = ReferenceDefinition{
+				DbName:           yyDollar[2].stringList[0],
 				TableName:        yyDollar[2].stringList[1],
 				KeyPartList:      yyDollar[3].keyPartList,
 				ReferenceOptions: yyDollar[4].item.(ReferenceOptions),
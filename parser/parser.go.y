@@ -1541,6 +1541,7 @@ ReferenceDefinition:
   REFERENCES TableName KeyPartList ReferenceOptions
   {
     $$ = ReferenceDefinition{
+      DbName: $2[0],
       TableName: $2[1],
       KeyPartList: $3,
       ReferenceOptions: $4.(ReferenceOptions),
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -1194,3 +1195,40 @@ func TestCreateTableWithPartitions(t *testing.T) {
 	b3, err := os.ReadFile("test/table/partition/output3.sql")
 	assert.Equal(t, string(b3), r[2].String())
 }
+
+func TestRenameHints(t *testing.T) {
+	p := NewParser(strings.NewReader(`
+CREATE DATABASE db1; USE db1;
+-- alternator:renamed-from t0
+CREATE TABLE t1 (
+    # alternator:renamed-from c0
+    c1 int,
+    c2 varchar(10) DEFAULT '-- alternator:renamed-from x', -- alternator:renamed-from ` + "`old c2`" + `
+    c3 int COMMENT 'not a hint',
+    /* alternator:renamed-from idx0 */
+    INDEX idx1 (c1, c2),
+    UNIQUE KEY uk1 (c3), -- alternator:renamed-from uk0
+    CONSTRAINT fk1 FOREIGN KEY (c1) REFERENCES t2 (id) -- alternator:renamed-from fk0
+);
+CREATE TABLE t2 (id int, CHECK (id > 0)) -- alternator:renamed-from chk0
+;
+`))
+	r, err := p.Parse()
+	require.NoError(t, err)
+	require.Len(t, r, 4)
+
+	t1 := r[2].(CreateTableStatement)
+	assert.Equal(t, "t0", t1.RenamedFrom)
+	columns := t1.GetColumns()
+	assert.Equal(t, "c0", columns[0].RenamedFrom)
+	assert.Equal(t, "old c2", columns[1].RenamedFrom)
+	assert.Equal(t, "", columns[2].RenamedFrom)
+	assert.Equal(t, "idx0", t1.GetIndexes()[0].RenamedFrom)
+	assert.Equal(t, "uk0", t1.GetUniqueKeys()[0].RenamedFrom)
+	assert.Equal(t, "fk0", t1.GetForeignKeys()[0].RenamedFrom)
+
+	// A hint at the end of a line is for the definition started last
+	t2 := r[3].(CreateTableStatement)
+	assert.Equal(t, "", t2.RenamedFrom)
+	assert.Equal(t, "chk0", t2.GetCheckConstraints()[0].RenamedFrom)
+}
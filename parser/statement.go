@@ -37,6 +37,8 @@ type CreateTableStatement struct {
 	CreateDefinitions []interface{}
 	TableOptions      TableOptions
 	Partitions        PartitionConfig
+	// RenamedFrom is the old table name given by "alternator:renamed-from" comment
+	RenamedFrom string
 }
 
 func (r CreateTableStatement) String() string {